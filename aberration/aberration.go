@@ -0,0 +1,230 @@
+// Package aberration flags days and weeks with more cases than expected,
+// using the EARS C1, C2 and C3 methods (Hutwagner et al. 2003) on daily
+// series and a simplified Farrington method (Farrington et al. 1996) on
+// weekly series.
+package aberration
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"math"
+	"time"
+)
+
+// Detection methods
+const (
+	C1         = "C1"
+	C2         = "C2"
+	C3         = "C3"
+	Farrington = "farrington"
+)
+
+const (
+	// earsBaseline is the number of days of the EARS baselines
+	earsBaseline = 7
+	// minFarringtonBaseline is the number of baseline weeks below which
+	// the Farrington method is not applied
+	minFarringtonBaseline = 5
+)
+
+// Config holds the thresholds of the methods
+type Config struct {
+	// EARSThreshold is the number of standard deviations above the baseline
+	// mean C1 and C2 signal at
+	EARSThreshold float64
+	// C3Threshold is the sum of the C2 excesses over three days C3 signals
+	// at
+	C3Threshold float64
+	// Years is the number of past years the Farrington baseline is drawn
+	// from, taking HalfWindow weeks either side of the same week
+	Years      int
+	HalfWindow int
+	// Z is the standard normal quantile of the Farrington upper bound
+	Z float64
+	// MinCount is the number of cases a week needs for Farrington to signal
+	MinCount int
+}
+
+// DefaultConfig returns the thresholds commonly used: 3 standard deviations
+// for C1 and C2, 2 for C3, and a 99% Farrington bound over 2 years of
+// baseline weeks with at least 5 cases
+func DefaultConfig() Config {
+	return Config{
+		EARSThreshold: 3,
+		C3Threshold:   2,
+		Years:         2,
+		HalfWindow:    3,
+		Z:             2.326348,
+		MinCount:      5,
+	}
+}
+
+// Daily runs C1, C2 and C3 on the daily counts of the unit for the days from
+// (inclusive) up to (exclusive) to. The counts must reach 11 days before from
+// so the baselines of every day are known. The standard deviation of a
+// baseline is taken as at least one case, so that a flat baseline does not
+// flag every single extra case.
+func Daily(counts []stores.CaseCount, unit string, from, to time.Time, cfg Config) []stores.Alert {
+	daily := dailySeries(counts)
+	var alerts []stores.Alert
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		d := day
+		observed := daily.Get(day)
+		for _, method := range []string{C1, C2} {
+			mean, sd := earsBaselineStats(daily, day, method)
+			score := (float64(observed) - mean) / sd
+			alerts = append(alerts, stores.Alert{
+				Date:            &d,
+				Period:          stores.PeriodDay,
+				Method:          method,
+				Unit:            unit,
+				Observed:        observed,
+				Expected:        mean,
+				SD:              sd,
+				BaselinePeriods: earsBaseline,
+				UpperBound:      mean + cfg.EARSThreshold*sd,
+				Score:           score,
+				Threshold:       cfg.EARSThreshold,
+				Signal:          score > cfg.EARSThreshold,
+			})
+		}
+
+		// C3 adds the C2 excess over one standard deviation of the last
+		// three days.
+		previous := 0.0
+		for i := 1; i <= 2; i++ {
+			previous += c2Excess(daily, day.AddDate(0, 0, -i))
+		}
+		mean, sd := earsBaselineStats(daily, day, C2)
+		score := previous + c2Excess(daily, day)
+		alerts = append(alerts, stores.Alert{
+			Date:            &d,
+			Period:          stores.PeriodDay,
+			Method:          C3,
+			Unit:            unit,
+			Observed:        observed,
+			Expected:        mean,
+			SD:              sd,
+			BaselinePeriods: earsBaseline,
+			UpperBound:      mean + sd*(1+math.Max(0, cfg.C3Threshold-previous)),
+			Score:           score,
+			Threshold:       cfg.C3Threshold,
+			Signal:          score > cfg.C3Threshold,
+		})
+	}
+	return alerts
+}
+
+// earsBaselineStats returns the mean and standard deviation of the 7 days
+// before the day, or before the 2 days preceding it for C2 and C3
+func earsBaselineStats(daily series.Daily, day time.Time, method string) (mean, sd float64) {
+	lag := 1
+	if method != C1 {
+		lag = 3
+	}
+	values := make([]float64, earsBaseline)
+	for i := range values {
+		values[i] = float64(daily.Get(day.AddDate(0, 0, -(lag + i))))
+	}
+	mean, sd = meanSD(values)
+	return mean, math.Max(sd, 1)
+}
+
+// c2Excess returns how far C2 is above one standard deviation on the day
+func c2Excess(daily series.Daily, day time.Time) float64 {
+	mean, sd := earsBaselineStats(daily, day, C2)
+	return math.Max(0, (float64(daily.Get(day))-mean)/sd-1)
+}
+
+// Weekly runs the Farrington method on the weekly counts of the unit for the
+// ISO weeks starting from (inclusive) up to (exclusive) to. The baseline is
+// made of the weeks around the same week of the past years; the weeks before
+// the first case of the series are left out of it, and weeks with too short a
+// baseline are not evaluated. The bound is computed on the 2/3 power scale,
+// with the variance of the baseline inflated by its overdispersion.
+func Weekly(counts []stores.CaseCount, unit string, from, to time.Time, cfg Config) []stores.Alert {
+	daily := dailySeries(counts)
+	first, ok := daily.First()
+	if !ok {
+		return nil
+	}
+	first = stores.WeekStart(first)
+
+	var alerts []stores.Alert
+	for week := stores.WeekStart(from); week.Before(to); week = week.AddDate(0, 0, 7) {
+		var baseline []float64
+		for y := 1; y <= cfg.Years; y++ {
+			for j := -cfg.HalfWindow; j <= cfg.HalfWindow; j++ {
+				start := week.AddDate(0, 0, -364*y+7*j)
+				if !start.Before(first) {
+					baseline = append(baseline, float64(daily.Sum(start.AddDate(0, 0, 6), 7)))
+				}
+			}
+		}
+		if len(baseline) < minFarringtonBaseline {
+			continue
+		}
+
+		w := week
+		observed := daily.Sum(week.AddDate(0, 0, 6), 7)
+		mean, sd := meanSD(baseline)
+		dispersion := 1.0
+		if mean > 0 {
+			dispersion = math.Max(1, sd*sd/mean)
+		}
+		// The variance of the count is that of the noise around the
+		// expected count plus that of its estimate.
+		variance := dispersion*mean + dispersion*mean/float64(len(baseline))
+		// The exceedance score is the count on the 2/3 power scale relative
+		// to the bound, signalling above one. Without any baseline cases
+		// the bound is zero and the score is the count itself.
+		upper, score := 0.0, float64(observed)
+		if mean > 0 {
+			upper = mean * math.Pow(1+2.0/3*cfg.Z*math.Sqrt(variance)/mean, 1.5)
+			score = (math.Pow(float64(observed), 2.0/3) - math.Pow(mean, 2.0/3)) /
+				(math.Pow(upper, 2.0/3) - math.Pow(mean, 2.0/3))
+		}
+		alerts = append(alerts, stores.Alert{
+			Date:            &w,
+			Period:          stores.PeriodWeek,
+			Method:          Farrington,
+			Unit:            unit,
+			Observed:        observed,
+			Expected:        mean,
+			SD:              sd,
+			BaselinePeriods: len(baseline),
+			UpperBound:      upper,
+			Score:           score,
+			Threshold:       1,
+			Signal:          float64(observed) > upper && observed >= cfg.MinCount,
+		})
+	}
+	return alerts
+}
+
+// dailySeries returns the counts keyed by day
+func dailySeries(counts []stores.CaseCount) series.Daily {
+	daily := series.Daily{}
+	for _, c := range counts {
+		if c.ReportingDate != nil {
+			daily.Add(*c.ReportingDate, c.Count)
+		}
+	}
+	return daily
+}
+
+// meanSD returns the mean and sample standard deviation of the values
+func meanSD(values []float64) (mean, sd float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	ss := 0.0
+	for _, v := range values {
+		ss += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(ss / float64(len(values)-1))
+}
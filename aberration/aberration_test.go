@@ -0,0 +1,114 @@
+package aberration
+
+import (
+	"covidstats/stores"
+	"covidstats/stores/storestest"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+// values returns the counts of the days, with value returning the count of
+// the i-th day
+func values(days int, value func(i int) int) []int {
+	vv := make([]int, days)
+	for i := range vv {
+		vv[i] = value(i)
+	}
+	return vv
+}
+
+func signals(alerts []stores.Alert, method string) []string {
+	var days []string
+	for _, a := range alerts {
+		if a.Method == method && a.Signal {
+			days = append(days, a.Date.Format("2006-01-02"))
+		}
+	}
+	return days
+}
+
+func TestDaily(t *testing.T) {
+	// Around 10 cases a day with a single spike on 2022-01-01, and three
+	// moderately raised days from 2022-01-10.
+	noise := []int{1, -1, 0, 2, -2, 0, 1}
+	cc := storestest.Counts("2021-12-15", values(30, func(i int) int {
+		switch {
+		case i == 17:
+			return 40
+		case i >= 26 && i <= 28:
+			return 14
+		}
+		return 10 + noise[i%7]
+	})...)
+	alerts := Daily(cc, stores.NationalUnit, date("2021-12-27"), date("2022-01-13"), DefaultConfig())
+	if len(alerts) != 17*3 {
+		t.Fatalf("expected 3 outcomes for each of 17 days, got %d", len(alerts))
+	}
+
+	if got := signals(alerts, C1); len(got) != 1 || got[0] != "2022-01-01" {
+		t.Errorf("expected C1 to signal on 2022-01-01, got %v", got)
+	}
+	if got := signals(alerts, C2); len(got) != 1 || got[0] != "2022-01-01" {
+		t.Errorf("expected C2 to signal on 2022-01-01, got %v", got)
+	}
+	c3 := signals(alerts, C3)
+	if len(c3) < 2 || c3[0] != "2022-01-01" || c3[len(c3)-1] != "2022-01-12" {
+		t.Errorf("expected C3 to signal on the spike and the raised days, got %v", c3)
+	}
+
+	for _, a := range alerts {
+		if a.Method == C1 && a.Date.Format("2006-01-02") == "2022-01-01" {
+			if a.Unit != stores.NationalUnit || a.Period != stores.PeriodDay || a.Observed != 40 {
+				t.Errorf("unexpected alert %+v", a)
+			}
+			if a.UpperBound >= 40 || a.Expected < 9 || a.Expected > 11 {
+				t.Errorf("unexpected baseline %+v", a)
+			}
+		}
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	// Around 10 cases a day from 2020-01-06 with a doubling in the week of
+	// 2022-02-07.
+	noise := []int{3, -2, 0, 1, -1, 2, -3, 0, 2, -2, 1}
+	cc := storestest.Counts("2020-01-06", values(800, func(i int) int {
+		if i >= 763 && i < 770 {
+			return 20
+		}
+		return 10 + noise[(i/7)%len(noise)]
+	})...)
+	alerts := Weekly(cc, "Cayo", date("2020-06-01"), date("2022-03-07"), DefaultConfig())
+
+	// The week of 2021-01-11 is the first whose baseline has 5 weeks, from
+	// 2020-01-06 to 2020-02-03.
+	if len(alerts) == 0 || alerts[0].Date.Format("2006-01-02") != "2021-01-11" {
+		t.Fatalf("expected the first evaluated week to be 2021-01-11, got %d alerts", len(alerts))
+	}
+	if got := signals(alerts, Farrington); len(got) != 1 || got[0] != "2022-02-07" {
+		t.Errorf("expected a signal in the week of 2022-02-07, got %v", got)
+	}
+	for _, a := range alerts {
+		if a.Date.Format("2006-01-02") == "2022-02-07" && (a.Observed != 140 || a.BaselinePeriods != 14 || a.Score <= 1) {
+			t.Errorf("unexpected alert %+v", a)
+		}
+	}
+}
+
+func TestWeekly_MinCount(t *testing.T) {
+	cc := storestest.Counts("2020-01-06", values(800, func(i int) int {
+		if i == 765 {
+			return 3
+		}
+		return 0
+	})...)
+	alerts := Weekly(cc, stores.NationalUnit, date("2022-01-03"), date("2022-03-07"), DefaultConfig())
+	if got := signals(alerts, Farrington); len(got) != 0 {
+		t.Errorf("expected no signal below the minimum count, got %v", got)
+	}
+}
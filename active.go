@@ -0,0 +1,148 @@
+package covidstats
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleFindRecoveries is the handler that returns the recoveries grouped by
+// date of recovery for a given year, month, ISO week or epidemiological week.
+func (s *Server) HandleFindRecoveries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleFindRecoveries")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	s.findStats(w, r, s.recoveriesService)
+}
+
+// activeCount are the cumulative counts on a day
+type activeCount struct {
+	Confirmed int `json:"confirmed"`
+	Recovered int `json:"recovered"`
+	Deaths    int `json:"deaths"`
+	Active    int `json:"active"`
+}
+
+type activeCases struct {
+	Date string `json:"date"`
+	activeCount
+	Districts map[string]activeCount `json:"districts"`
+}
+
+// measures holds a daily series for the nation and each district
+type measures struct {
+	national  series.Daily
+	districts map[string]series.Daily
+}
+
+func newMeasures(cases []stores.CasesCountByDate) measures {
+	m := measures{national: series.Daily{}, districts: map[string]series.Daily{}}
+	for _, d := range stores.Districts() {
+		m.districts[string(d)] = series.Daily{}
+	}
+	for _, c := range cases {
+		if c.ReportingDate == nil {
+			continue
+		}
+		m.national.Add(*c.ReportingDate, c.Count)
+		for d, n := range c.Districts {
+			if m.districts[d] == nil {
+				m.districts[d] = series.Daily{}
+			}
+			m.districts[d].Add(*c.ReportingDate, n)
+		}
+	}
+	return m
+}
+
+// activeSeries returns the active cases of every day from from up to to.
+// The counts are accumulated from the first day with data, so the days of the
+// range before it are zero.
+func activeSeries(confirmed, recovered, deaths measures, from, to time.Time) []activeCases {
+	start := from
+	if first, ok := confirmed.national.First(); ok && first.Before(from) {
+		start = first
+	}
+	total := activeCount{}
+	districts := map[string]activeCount{}
+	result := []activeCases{}
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		total = total.add(confirmed.national.Get(day), recovered.national.Get(day), deaths.national.Get(day))
+		for d := range confirmed.districts {
+			districts[d] = districts[d].add(
+				confirmed.districts[d].Get(day),
+				recovered.districts[d].Get(day),
+				deaths.districts[d].Get(day),
+			)
+		}
+		if day.Before(from) {
+			continue
+		}
+		a := activeCases{
+			Date:        day.Format(series.Layout),
+			activeCount: total,
+			Districts:   map[string]activeCount{},
+		}
+		for d, c := range districts {
+			a.Districts[d] = c
+		}
+		result = append(result, a)
+	}
+	return result
+}
+
+func (a activeCount) add(confirmed, recovered, deaths int) activeCount {
+	a.Confirmed += confirmed
+	a.Recovered += recovered
+	a.Deaths += deaths
+	a.Active = a.Confirmed - a.Recovered - a.Deaths
+	return a
+}
+
+// HandleActiveCases is the handler that returns the daily active cases,
+// nationally and per district, for the requested date range.
+func (s *Server) HandleActiveCases(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleActiveCases")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Active cases depend on every case since the start of the outbreak.
+	end := to.AddDate(0, 0, 1)
+	var all [3][]stores.CasesCountByDate
+	for i, svc := range []*stores.CasesByDateService{s.casesService, s.recoveriesService, s.deathsService} {
+		cases, err := svc.FindByDateRange(r.Context(), time.Time{}, end)
+		if err != nil {
+			s.logger.WithFields(log.Fields{
+				"from": from,
+				"to":   to,
+			}).
+				WithError(err).
+				Error("FindByDateRange failed")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		all[i] = cases
+	}
+
+	result := activeSeries(newMeasures(all[0]), newMeasures(all[1]), newMeasures(all[2]), from, to)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
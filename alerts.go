@@ -0,0 +1,91 @@
+package covidstats
+
+import (
+	"covidstats/aberration"
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// alertFilter selects the alerts returned by the unit, method and period
+// query parameters. Empty values match every alert.
+type alertFilter struct {
+	unit, method, period string
+}
+
+// parseAlertFilter reads and validates the alert query parameters
+func parseAlertFilter(r *http.Request) (alertFilter, bool) {
+	q := r.URL.Query()
+	f := alertFilter{unit: q.Get("unit"), method: q.Get("method"), period: q.Get("period")}
+	if f.unit != "" && f.unit != stores.NationalUnit {
+		if _, err := parseDistrict(f.unit); err != nil {
+			return f, false
+		}
+	}
+	switch f.method {
+	case "", aberration.C1, aberration.C2, aberration.C3, aberration.Farrington:
+	default:
+		return f, false
+	}
+	switch f.period {
+	case "", stores.PeriodDay, stores.PeriodWeek:
+	default:
+		return f, false
+	}
+	return f, true
+}
+
+// matches tells whether the alert is selected by the filter
+func (f alertFilter) matches(a stores.Alert) bool {
+	return (f.unit == "" || f.unit == a.Unit) &&
+		(f.method == "" || f.method == a.Method) &&
+		(f.period == "" || f.period == a.Period)
+}
+
+// HandleAlerts is the handler that returns the aberration signals of the days
+// and weeks starting in the requested date range, with the baseline and
+// threshold they were raised with.
+func (s *Server) HandleAlerts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleAlerts")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, ok := parseAlertFilter(r)
+	if !ok {
+		http.Error(w, "unit must be National or a district, method one of C1, C2, C3 or farrington and period day or week", http.StatusBadRequest)
+		return
+	}
+
+	alerts, findErr := s.alerts.FindSignals(r.Context(), from, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindSignals failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	result := []stores.Alert{}
+	for _, a := range alerts {
+		if filter.matches(a) {
+			result = append(result, a)
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
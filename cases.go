@@ -0,0 +1,92 @@
+package covidstats
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// bucketCount is the total of the cases in a bucket of days
+type bucketCount struct {
+	Period    string         `json:"period"`
+	From      time.Time      `json:"from"`
+	To        time.Time      `json:"to"`
+	Count     int            `json:"count"`
+	Districts map[string]int `json:"districts"`
+	// Partial is set when the bucket reaches past the requested range, as
+	// only the days within the range are summed
+	Partial bool `json:"partial"`
+}
+
+// HandleCases is the handler that returns the cases of the requested date
+// range summed by day, ISO week, epidemiological week, month or year, as set
+// by the granularity query parameter. Days are the default.
+func (s *Server) HandleCases(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleCases")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+	granularity := q.Get("granularity")
+	if granularity == "" {
+		granularity = series.GranularityDay
+	}
+	if _, err := series.BucketOf(from, granularity); err != nil {
+		http.Error(w, "granularity must be day, week, epiweek, month or year", http.StatusBadRequest)
+		return
+	}
+
+	cases, findErr := s.casesService.FindByDateRange(r.Context(), from, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	national := dailySeries(cases, "")
+	districts := map[string]series.Daily{}
+	for _, d := range stores.Districts() {
+		districts[string(d)] = dailySeries(cases, string(d))
+	}
+	result := []bucketCount{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		b, _ := series.BucketOf(day, granularity)
+		if len(result) == 0 || result[len(result)-1].Period != b.Key {
+			bc := bucketCount{
+				Period:    b.Key,
+				From:      b.From,
+				To:        b.To,
+				Districts: map[string]int{},
+				Partial:   b.From.Before(from) || b.To.After(to),
+			}
+			result = append(result, bc)
+		}
+		bc := &result[len(result)-1]
+		bc.Count += national.Get(day)
+		for d, daily := range districts {
+			bc.Districts[d] += daily.Get(day)
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
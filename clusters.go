@@ -0,0 +1,73 @@
+package covidstats
+
+import (
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gorilla/mux"
+)
+
+// clusterStats are the weekly cluster statistics along with the share of new
+// cases with a known source and the secondary cases per source case
+type clusterStats struct {
+	stores.ClusterStats
+	LinkedShare        *float64 `json:"linkedShare"`
+	SecondaryPerSource *float64 `json:"secondaryPerSource"`
+}
+
+// HandleClusterStats is the handler that returns the weekly transmission
+// chain and cluster statistics for a year or an ISO week, or for an
+// epidemiological year or week.
+func (s *Server) HandleClusterStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleClusterStats")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	vars := mux.Vars(r)
+	wq, queryErr := parseWeeklyQuery(vars)
+	if queryErr != nil {
+		http.Error(w, queryErr.Error(), http.StatusBadRequest)
+		return
+	}
+	svc := s.clusters
+	if wq.epi {
+		svc = s.epiWeekClusters
+	}
+	var (
+		stats   []stores.ClusterStats
+		findErr error
+	)
+	if wq.week == "" {
+		stats, findErr = svc.FindByYear(r.Context(), wq.year)
+	} else {
+		stats, findErr = svc.FindByWeek(r.Context(), wq.week)
+	}
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"vars": vars,
+		}).
+			WithError(findErr).
+			Error("finding cluster stats failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]clusterStats, 0, len(stats))
+	for _, st := range stats {
+		result = append(result, clusterStats{
+			ClusterStats:       st,
+			LinkedShare:        share(st.LinkedCases, st.NewCases),
+			SecondaryPerSource: share(st.SecondaryCases, st.SourceCases),
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -1,3 +1,123 @@
 package main
 
-func main() {}
+import (
+	"context"
+	"covidstats/aberration"
+	"covidstats/forecast"
+	"covidstats/pipeline"
+	"covidstats/stores"
+	"flag"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const isoLayout = "2006-01-02"
+
+func main() {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	fromStr := flag.String("from", today.AddDate(0, 0, -30).Format(isoLayout), "first reporting date to sync (yyyy-mm-dd)")
+	toStr := flag.String("to", today.Format(isoLayout), "last reporting date to sync (yyyy-mm-dd)")
+	addressTypes := flag.String("address-types", stores.UsualPlaceOfResidence, "comma separated Go.Data address types used to resolve a residence, in order of precedence")
+	ageBands := flag.String("age-bands", "0-4,5-17,18-39,40-59,60+", "comma separated age bands used for the demographic breakdown")
+	isolationDays := flag.Int("isolation-days", 14, "days after reporting a case without an outcome is considered recovered")
+	classifications := flag.String("classifications", "confirmed,probable,suspect", "comma separated case classifications tracked in the stats")
+	published := flag.String("published", "confirmed", "comma separated case classifications counted toward the published totals")
+	careTypes := flag.String("care-types",
+		stores.DateTypeHospitalization+"="+stores.CareHospital+","+stores.DateTypeICU+"="+stores.CareICU,
+		"comma separated Go.Data date range type=care setting (hospital or icu) pairs")
+	vaccineWaitingDays := flag.Int("vaccine-waiting-days", 14, "days after a vaccine dose before it counts toward the vaccination status")
+	forecastWindow := flag.Int("forecast-window", 28, "days of the case series the forecast trend is fitted to")
+	flag.Parse()
+
+	logger := log.New()
+	logger.SetFormatter(&log.JSONFormatter{})
+	logger.SetOutput(os.Stdout)
+
+	from, err := time.Parse(isoLayout, *fromStr)
+	if err != nil {
+		logger.Fatalf("invalid from date: %v", err)
+	}
+	to, err := time.Parse(isoLayout, *toStr)
+	if err != nil {
+		logger.Fatalf("invalid to date: %v", err)
+	}
+	tracked, err := stores.ParseClassifications(*classifications)
+	if err != nil {
+		logger.Fatalf("invalid classifications: %v", err)
+	}
+	publishedClassifications, err := stores.ParseClassifications(*published)
+	if err != nil {
+		logger.Fatalf("invalid published classifications: %v", err)
+	}
+	care, err := stores.ParseCareTypes(*careTypes)
+	if err != nil {
+		logger.Fatalf("invalid care types: %v", err)
+	}
+	bands, err := stores.ParseAgeBands(*ageBands)
+	if err != nil {
+		logger.Fatalf("invalid age bands: %v", err)
+	}
+
+	forecastConfig := forecast.DefaultConfig()
+	forecastConfig.Window = *forecastWindow
+	if err := forecastConfig.Validate(); err != nil {
+		logger.Fatalf("invalid forecast window: %v", err)
+	}
+
+	vaccinationRule := stores.DefaultVaccinationRule()
+	vaccinationRule.WaitingDays = *vaccineWaitingDays
+
+	ctx := context.Background()
+	source, err := stores.NewMongoStore(os.Getenv("MONGO_URI"), os.Getenv("MONGO_DB"))
+	if err != nil {
+		logger.Fatalf("mongo connection failed: %v", err)
+	}
+	if err := source.Connect(ctx); err != nil {
+		logger.Fatalf("failed to connect to mongo: %v", err)
+	}
+	defer source.Disconnect(ctx) //nolint:errcheck
+	source.AddressTypes = strings.Split(*addressTypes, ",")
+
+	fsClient, err := stores.CreateFirestoreDB(ctx, os.Getenv("GCP_PROJECT_ID"))
+	if err != nil {
+		logger.Fatalf("failed to create firestore db: %v", err)
+	}
+
+	sync := pipeline.Sync{
+		Source:            &source,
+		OutbreakID:        os.Getenv("OUTBREAK_ID"),
+		Cases:             stores.NewCasesByDateService(fsClient, "covid_cases_stats"),
+		Classifications:   tracked,
+		Published:         publishedClassifications,
+		Deaths:            stores.NewCasesByDateService(fsClient, "covid_deaths_stats"),
+		Recoveries:        stores.NewCasesByDateService(fsClient, "covid_recoveries_stats"),
+		IsolationDays:     *isolationDays,
+		Hospital:          stores.NewHospitalService(fsClient, "covid_hospital_stats"),
+		CareTypes:         care,
+		Tests:             stores.NewTestsService(fsClient, "covid_tests_stats"),
+		ContactTracing:    stores.NewContactTracingService(fsClient, "covid_contact_tracing_stats"),
+		VaccinationStatus: stores.NewVaccinationStatusService(fsClient, "covid_vaccination_status_stats"),
+		VaccinationRule:   vaccinationRule,
+		Onset:             stores.NewCasesByDateService(fsClient, "covid_onset_stats"),
+		ReportingDelays:   stores.NewReportingDelayService(fsClient, "covid_reporting_delay_stats"),
+		Clusters:          stores.NewClusterService(fsClient, "covid_cluster_stats"),
+		Demographics:      stores.NewDemographicsService(fsClient, "covid_cases_demographics"),
+		AgeBands:          bands,
+		Snapshots:         stores.NewSnapshotService(fsClient, "covid_cases_snapshots"),
+		Forecasts:         stores.NewForecastService(fsClient, "covid_cases_forecasts"),
+		Forecast:          forecastConfig,
+		Alerts:            stores.NewAlertService(fsClient, "covid_alerts"),
+		Alert:             aberration.DefaultConfig(),
+		Logger:            logger,
+
+		EpiWeekVaccinationStatus: stores.NewVaccinationStatusService(fsClient, "covid_vaccination_status_epiweek_stats"),
+		EpiWeekReportingDelays:   stores.NewReportingDelayService(fsClient, "covid_reporting_delay_epiweek_stats"),
+		EpiWeekClusters:          stores.NewClusterService(fsClient, "covid_cluster_epiweek_stats"),
+	}
+	if err := sync.Run(ctx, from, to.AddDate(0, 0, 1)); err != nil {
+		logger.Fatalf("sync failed: %v", err)
+	}
+}
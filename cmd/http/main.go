@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"covidstats"
+	"covidstats/stores"
 	"os"
 
 	log "github.com/sirupsen/logrus"
@@ -17,7 +18,25 @@ func main() {
 	logger.SetOutput(os.Stdout)
 
 	logger.Infof("GOOGLE_APPLICATION_CREDENTIALS %s", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
-	server, err := covidstats.NewServer(ctx, logger, projectID)
+	var opts []covidstats.Option
+	// POPULATION_CSV is the admin_unit,year,population file read by
+	// stores.LoadPopulation. Without it the incidence rates are left out.
+	if path := os.Getenv("POPULATION_CSV"); path != "" {
+		pop, err := stores.OpenPopulation(path)
+		if err != nil {
+			log.Fatalf("failed to load population: %v", err)
+		}
+		opts = append(opts, covidstats.WithPopulation(pop))
+	}
+	if path := os.Getenv("DISTRICT_BOUNDARIES"); path != "" {
+		boundaries, err := stores.OpenBoundaries(path)
+		if err != nil {
+			log.Fatalf("failed to load district boundaries: %v", err)
+		}
+		opts = append(opts, covidstats.WithBoundaries(boundaries))
+	}
+
+	server, err := covidstats.NewServer(ctx, logger, projectID, opts...)
 	if err != nil {
 		log.Fatalf("failed to start server: %v", err)
 	}
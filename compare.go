@@ -0,0 +1,159 @@
+package covidstats
+
+import (
+	"covidstats/nowcast"
+	"covidstats/series"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// trendTolerance is the relative change under which the cases are stable
+const trendTolerance = 0.05
+
+// periodTotal is the total of the cases over a period
+type periodTotal struct {
+	Period string    `json:"period"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Cases  int       `json:"cases"`
+	// Incomplete is set when the period reaches days whose counts are
+	// not final, or days up to today without stored counts, with the
+	// reasons in Warnings
+	Incomplete  bool     `json:"incomplete"`
+	MissingDays int      `json:"missingDays"`
+	Warnings    []string `json:"warnings"`
+}
+
+// comparison is the change of the cases from the previous period to the
+// current one
+type comparison struct {
+	Current  periodTotal `json:"current"`
+	Previous periodTotal `json:"previous"`
+	Change   int         `json:"change"`
+	// PercentChange is left out when the previous period has no cases
+	PercentChange *float64 `json:"percentChange"`
+	// Trend is up, down or stable
+	Trend string `json:"trend"`
+}
+
+// newPeriodTotal sums the cases of the period and flags it when its counts
+// are still coming in or some of its days were never synced. daily holds a
+// value for every stored day, zero or not.
+func newPeriodTotal(p series.Period, daily series.Daily, today time.Time) periodTotal {
+	total := periodTotal{Period: p.String(), From: p.From, To: p.To, Cases: p.Sum(daily), Warnings: []string{}}
+	for day := p.From; !day.After(p.To) && !day.After(today); day = day.AddDate(0, 0, 1) {
+		if _, ok := daily[day.Format(series.Layout)]; !ok {
+			total.MissingDays++
+		}
+	}
+	if total.MissingDays > 0 {
+		total.Warnings = append(total.Warnings, fmt.Sprintf("%d days of the period have no stored counts", total.MissingDays))
+	}
+	switch {
+	case p.To.After(today):
+		total.Warnings = append(total.Warnings, "the period ends after today")
+	case !p.To.Before(today):
+		total.Warnings = append(total.Warnings, "the cases of today are still being reported")
+	case !p.To.Before(today.AddDate(0, 0, -nowcast.DefaultMaxDelay)):
+		total.Warnings = append(total.Warnings,
+			fmt.Sprintf("the period ends within %d days of today, late cases may still be reported", nowcast.DefaultMaxDelay))
+	}
+	total.Incomplete = len(total.Warnings) > 0
+	return total
+}
+
+// trend returns the direction of the change
+func trend(previous, current int) string {
+	diff := float64(current - previous)
+	if math.Abs(diff) <= trendTolerance*float64(previous) {
+		return "stable"
+	}
+	if diff > 0 {
+		return "up"
+	}
+	return "down"
+}
+
+// HandleComparePeriods is the handler that compares the cases of the current
+// query parameter period with those of the previous one. Periods are ISO
+// weeks (yyyy-Www), months (yyyy-mm) or ranges (yyyy-mm-dd/yyyy-mm-dd); the
+// previous period defaults to the one just before the current. The district
+// parameter compares the cases of a district.
+func (s *Server) HandleComparePeriods(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleComparePeriods")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	current, currentErr := series.ParsePeriod(q.Get("current"))
+	if currentErr != nil {
+		http.Error(w, "current must be an ISO week (yyyy-Www), a month (yyyy-mm) or a range (yyyy-mm-dd/yyyy-mm-dd)", http.StatusBadRequest)
+		return
+	}
+	previous := current.Previous()
+	if value := q.Get("previous"); value != "" {
+		var previousErr error
+		if previous, previousErr = series.ParsePeriod(value); previousErr != nil {
+			http.Error(w, "previous must be an ISO week (yyyy-Www), a month (yyyy-mm) or a range (yyyy-mm-dd/yyyy-mm-dd)", http.StatusBadRequest)
+			return
+		}
+	}
+	for _, p := range []series.Period{previous, current} {
+		if p.Kind != series.PeriodRange {
+			continue
+		}
+		if checkRange(p.From, p.To) != nil {
+			http.Error(w, fmt.Sprintf("%s: a range must span at most %d days", p, maxRangeDays), http.StatusBadRequest)
+			return
+		}
+	}
+	district, districtErr := parseDistrict(q.Get("district"))
+	if districtErr != nil {
+		http.Error(w, districtErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	daily := series.Daily{}
+	for _, p := range []series.Period{previous, current} {
+		cases, findErr := s.casesService.FindByDateRange(r.Context(), p.From, p.To.AddDate(0, 0, 1))
+		if findErr != nil {
+			s.logger.WithFields(log.Fields{
+				"from": p.From,
+				"to":   p.To,
+			}).
+				WithError(findErr).
+				Error("FindByDateRange failed")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		// Overlapping periods share days, which must only be counted once.
+		for day, count := range dailySeries(cases, district) {
+			daily[day] = count
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	result := comparison{
+		Current:  newPeriodTotal(current, daily, today),
+		Previous: newPeriodTotal(previous, daily, today),
+	}
+	result.Change = result.Current.Cases - result.Previous.Cases
+	if result.Previous.Cases > 0 {
+		pct := float64(result.Change) * 100 / float64(result.Previous.Cases)
+		result.PercentChange = &pct
+	}
+	result.Trend = trend(result.Previous.Cases, result.Current.Cases)
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,59 @@
+package covidstats
+
+import (
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// contactTracingStats is the contact tracing activity on a day along with
+// the share of contacts seen and of new cases that were known contacts
+type contactTracingStats struct {
+	stores.ContactTracingCount
+	SeenShare         *float64 `json:"seenShare"`
+	FromContactsShare *float64 `json:"fromContactsShare"`
+}
+
+// HandleContactTracingStats is the handler that returns the daily contact
+// tracing performance for the requested date range.
+func (s *Server) HandleContactTracingStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleContactTracingStats")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	counts, findErr := s.contactTracing.FindByDateRange(r.Context(), from, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]contactTracingStats, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, contactTracingStats{
+			ContactTracingCount: c,
+			SeenShare:           share(c.Seen, c.FollowUps),
+			FromContactsShare:   share(c.NewCasesFromContacts, c.NewCases),
+		})
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
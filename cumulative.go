@@ -0,0 +1,74 @@
+package covidstats
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleCumulative is the handler that returns the zero filled daily counts
+// of the requested date range with their running totals since the start of
+// the outbreak. The measure query parameter selects cases (default), deaths,
+// recoveries or onset, and the district parameter the counts of a district.
+// The range defaults to the whole outbreak.
+func (s *Server) HandleCumulative(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleCumulative")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	svc, ok := s.measureServices()[q.Get("measure")]
+	if !ok {
+		http.Error(w, "measure must be cases, deaths, recoveries or onset", http.StatusBadRequest)
+		return
+	}
+	to, toErr := parseDate(q.Get("to"), time.Now().UTC().Truncate(24*time.Hour))
+	if toErr != nil {
+		http.Error(w, "to must be a date (yyyy-mm-dd)", http.StatusBadRequest)
+		return
+	}
+	district, districtErr := parseDistrict(q.Get("district"))
+	if districtErr != nil {
+		http.Error(w, districtErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The running totals depend on every count since the start of the
+	// outbreak.
+	cases, findErr := svc.FindByDateRange(r.Context(), time.Time{}, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"measure": q.Get("measure"),
+			"to":      to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	daily := dailySeries(cases, district)
+	// Synced days are zero filled, so the outbreak starts on the first case.
+	start, ok := daily.FirstNonZero()
+	if !ok {
+		start = to
+	}
+	from, fromErr := parseDate(q.Get("from"), start)
+	if fromErr != nil {
+		http.Error(w, "from and to must be dates (yyyy-mm-dd) with from before to", http.StatusBadRequest)
+		return
+	}
+	if err := checkRange(from, to); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(daily.Points(from, to)); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,129 @@
+package covidstats
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// dailyCount is the count of a day along with its rolling statistics. The
+// mean is left out when its window reaches past today.
+type dailyCount struct {
+	Date  string   `json:"date"`
+	Count int      `json:"count"`
+	Mean7 *float64 `json:"mean7"`
+	Sum14 int      `json:"sum14"`
+}
+
+// parseWindow reads the average query parameter
+func parseWindow(value string) (series.Window, error) {
+	switch value {
+	case "", "trailing":
+		return series.Trailing, nil
+	case "centered":
+		return series.Centered, nil
+	default:
+		return series.Trailing, fmt.Errorf("%w: average=%s", errInvalidParam, value)
+	}
+}
+
+// parseDistrict reads the district query parameter
+func parseDistrict(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+	for _, d := range stores.Districts() {
+		if string(d) == value {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("%w: district=%s", errInvalidParam, value)
+}
+
+// dailySeries returns the daily counts, nationally or for a district, keyed
+// by day
+func dailySeries(cases []stores.CasesCountByDate, district string) series.Daily {
+	daily := series.Daily{}
+	for _, c := range cases {
+		if c.ReportingDate == nil {
+			continue
+		}
+		if district == "" {
+			daily.Add(*c.ReportingDate, c.Count)
+		} else {
+			daily.Add(*c.ReportingDate, c.Districts[district])
+		}
+	}
+	return daily
+}
+
+// HandleDailySeries is the handler that returns the zero filled daily cases
+// for the requested date range, with their 7 day mean and 14 day sum. The
+// average query parameter selects a trailing (default) or centered mean, and
+// the district parameter the cases of a district.
+func (s *Server) HandleDailySeries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleDailySeries")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+	window, windowErr := parseWindow(q.Get("average"))
+	if windowErr != nil {
+		http.Error(w, windowErr.Error(), http.StatusBadRequest)
+		return
+	}
+	district, districtErr := parseDistrict(q.Get("district"))
+	if districtErr != nil {
+		http.Error(w, districtErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Fetch the days the windows of the first and last days reach.
+	first, _ := series.Trailing.Span(from, 14)
+	_, last := window.Span(to, 7)
+	cases, findErr := s.casesService.FindByDateRange(r.Context(), first, last.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	daily := dailySeries(cases, district)
+	result := []dailyCount{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		dc := dailyCount{
+			Date:  day.Format(series.Layout),
+			Count: daily.Get(day),
+			Sum14: daily.Sum(day, 14),
+		}
+		if _, end := window.Span(day, 7); !end.After(today) {
+			mean := daily.Mean(day, 7, window)
+			dc.Mean7 = &mean
+		}
+		result = append(result, dc)
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,17 @@
+package covidstats
+
+import (
+	"net/http"
+)
+
+// HandleFindDeaths is the handler that returns the deaths grouped by date of
+// outcome for a given year, month, ISO week or epidemiological week.
+func (s *Server) HandleFindDeaths(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleFindDeaths")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	s.findStats(w, r, s.deathsService)
+}
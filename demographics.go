@@ -0,0 +1,65 @@
+package covidstats
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleDemographics is the handler that returns the age group and sex
+// breakdown of the cases reported on a day, in an ISO week or in a month.
+func (s *Server) HandleDemographics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleDemographics")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	vars := mux.Vars(r)
+	var (
+		result  stores.Demographics
+		findErr error
+	)
+	switch {
+	case vars["day"] != "":
+		day, err := time.Parse(series.Layout, vars["day"])
+		if err != nil {
+			http.Error(w, "day must be a date (yyyy-mm-dd)", http.StatusBadRequest)
+			return
+		}
+		result, findErr = s.demographics.FindByDay(r.Context(), day)
+	case vars["week"] != "":
+		week, err := parseWeek(vars["week"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, findErr = s.demographics.FindByWeek(r.Context(), week)
+	default:
+		if _, err := parseMonth(vars["month"]); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		result, findErr = s.demographics.FindByMonth(r.Context(), vars["month"])
+	}
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"vars": vars,
+		}).
+			WithError(findErr).
+			Error("finding demographics failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
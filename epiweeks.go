@@ -0,0 +1,166 @@
+package covidstats
+
+import (
+	"context"
+	"covidstats/series"
+	"covidstats/stores"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// epiWeekCount is the total of an MMWR epidemiological week
+type epiWeekCount struct {
+	EpiWeek string    `json:"epiWeek"`
+	Year    int       `json:"year"`
+	Week    int       `json:"week"`
+	From    time.Time `json:"from"`
+	To      time.Time `json:"to"`
+	Count   int       `json:"count"`
+}
+
+// measureServices returns the stored daily series by measure name, cases
+// being the default
+func (s *Server) measureServices() map[string]*stores.CasesByDateService {
+	return map[string]*stores.CasesByDateService{
+		"":           s.casesService,
+		"cases":      s.casesService,
+		"deaths":     s.deathsService,
+		"recoveries": s.recoveriesService,
+		"onset":      s.onsetService,
+	}
+}
+
+// nationalMeasure reads the daily series of a measure that is only kept
+// nationally, from (inclusive) up to (exclusive) the given dates
+type nationalMeasure func(ctx context.Context, from, to time.Time) (series.Daily, error)
+
+// nationalMeasures returns the daily series without district counts by
+// measure name
+func (s *Server) nationalMeasures() map[string]nationalMeasure {
+	tests := func(positive bool) nationalMeasure {
+		return func(ctx context.Context, from, to time.Time) (series.Daily, error) {
+			counts, err := s.tests.FindByDateRange(ctx, from, to)
+			daily := series.Daily{}
+			for _, c := range counts {
+				if positive {
+					daily.Add(*c.Date, c.Positive)
+				} else {
+					daily.Add(*c.Date, c.Tests)
+				}
+			}
+			return daily, err //nolint:wrapcheck
+		}
+	}
+	admissions := func(care string) nationalMeasure {
+		return func(ctx context.Context, from, to time.Time) (series.Daily, error) {
+			counts, err := s.hospital.FindByDateRange(ctx, from, to)
+			daily := series.Daily{}
+			for _, c := range counts {
+				daily.Add(*c.Date, c.Admissions[care])
+			}
+			return daily, err //nolint:wrapcheck
+		}
+	}
+	return map[string]nationalMeasure{
+		"tests":         tests(false),
+		"positiveTests": tests(true),
+		// A transfer from the ward to ICU is an admission to each, so the
+		// care settings are not added up.
+		"hospitalAdmissions": admissions(stores.CareHospital),
+		"icuAdmissions":      admissions(stores.CareICU),
+		"newContacts": func(ctx context.Context, from, to time.Time) (series.Daily, error) {
+			counts, err := s.contactTracing.FindByDateRange(ctx, from, to)
+			daily := series.Daily{}
+			for _, c := range counts {
+				daily.Add(*c.Date, c.NewContacts)
+			}
+			return daily, err //nolint:wrapcheck
+		},
+	}
+}
+
+// HandleEpiWeeks is the handler that returns the weekly totals by MMWR
+// epidemiological week, Sunday to Saturday, of the weeks overlapping the
+// requested date range. The measure query parameter selects cases (default),
+// deaths, recoveries, onset, tests, positiveTests, hospitalAdmissions,
+// icuAdmissions or newContacts, and the district parameter the counts of a
+// district for the measures that have them.
+func (s *Server) HandleEpiWeeks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleEpiWeeks")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	svc, isCases := s.measureServices()[q.Get("measure")]
+	national, isNational := s.nationalMeasures()[q.Get("measure")]
+	if !isCases && !isNational {
+		http.Error(w, "measure must be cases, deaths, recoveries, onset, tests, positiveTests, hospitalAdmissions, icuAdmissions or newContacts", http.StatusBadRequest)
+		return
+	}
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+	district, districtErr := parseDistrict(q.Get("district"))
+	if districtErr != nil {
+		http.Error(w, districtErr.Error(), http.StatusBadRequest)
+		return
+	}
+	if isNational && district != "" {
+		http.Error(w, fmt.Sprintf("%s has no district counts", q.Get("measure")), http.StatusBadRequest)
+		return
+	}
+
+	// Whole weeks are returned, even when the range starts or ends mid week.
+	start := series.EpiWeekStart(from)
+	end := series.EpiWeekStart(to).AddDate(0, 0, 7)
+	var (
+		daily   series.Daily
+		findErr error
+	)
+	if isCases {
+		var cases []stores.CasesCountByDate
+		cases, findErr = svc.FindByDateRange(r.Context(), start, end)
+		daily = dailySeries(cases, district)
+	} else {
+		daily, findErr = national(r.Context(), start, end)
+	}
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"measure": q.Get("measure"),
+			"from":    from,
+			"to":      to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result := []epiWeekCount{}
+	for week := start; week.Before(end); week = week.AddDate(0, 0, 7) {
+		year, number := series.EpiWeek(week)
+		saturday := week.AddDate(0, 0, 6)
+		result = append(result, epiWeekCount{
+			EpiWeek: series.EpiWeekKey(week),
+			Year:    year,
+			Week:    number,
+			From:    week,
+			To:      saturday,
+			Count:   daily.Sum(saturday, 7),
+		})
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
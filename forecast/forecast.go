@@ -0,0 +1,99 @@
+// Package forecast projects the daily case series a few weeks ahead by
+// extrapolating a quasi-Poisson log-linear trend fitted to the recent days.
+package forecast
+
+import (
+	"covidstats/growth"
+	"covidstats/series"
+	"covidstats/stores"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidConfig is returned when the forecast parameters are unusable
+var ErrInvalidConfig = errors.New("invalid forecast config")
+
+// ErrNoCases is returned when the fitted days have no cases to extrapolate
+var ErrNoCases = errors.New("no cases to forecast from")
+
+// Model names the model the forecasts are made with
+const Model = "quasi-poisson-log-linear"
+
+// z95 is the standard normal quantile of the 95% prediction interval
+const z95 = 1.959964
+
+// Config holds the parameters of the forecast
+type Config struct {
+	// Window is the number of days the trend is fitted to
+	Window int
+	// Horizon is the number of days forecast after the last fitted day
+	Horizon int
+}
+
+// DefaultConfig returns a config fitting the last 28 days and forecasting
+// 21 days ahead
+func DefaultConfig() Config {
+	return Config{Window: 28, Horizon: 21}
+}
+
+// Validate checks the window and horizon are long enough to forecast with
+func (c Config) Validate() error {
+	if c.Window < 7 || c.Horizon < 1 {
+		return fmt.Errorf("%w: window must be at least 7 days and horizon at least 1", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// Forecast fits the trend to the window of days ending on, and including,
+// end and forecasts every day up to the horizon. Days missing from the series
+// count as zero cases. The prediction intervals account for the uncertainty
+// of the trend and for the quasi-Poisson noise of the daily counts. When the
+// trend cannot be fitted, or extrapolates beyond what a float can hold, the
+// error wraps growth.ErrNoFit.
+func Forecast(counts []stores.CaseCount, end time.Time, cfg Config) (stores.Forecast, error) {
+	if err := cfg.Validate(); err != nil {
+		return stores.Forecast{}, err
+	}
+
+	daily := series.Daily{}
+	for _, c := range counts {
+		if c.ReportingDate != nil {
+			daily.Add(*c.ReportingDate, c.Count)
+		}
+	}
+	from := end.AddDate(0, 0, -(cfg.Window - 1))
+	if daily.Sum(end, cfg.Window) == 0 {
+		return stores.Forecast{}, fmt.Errorf("%w: %s to %s", ErrNoCases, from.Format(series.Layout), end.Format(series.Layout))
+	}
+	y := make([]float64, cfg.Window)
+	for i := range y {
+		y[i] = float64(daily.Get(from.AddDate(0, 0, i)))
+	}
+
+	m, err := growth.FitLogLinear(y)
+	if err != nil {
+		return stores.Forecast{}, fmt.Errorf("forecast %s to %s: %w", from.Format(series.Layout), end.Format(series.Layout), err)
+	}
+	fc := stores.Forecast{Model: Model, FittedFrom: &from, FittedTo: &end}
+	for h := 1; h <= cfg.Horizon; h++ {
+		eta, variance := m.Predict(float64(cfg.Window - 1 + h))
+		mean := math.Exp(eta)
+		// Delta method variance of the count: the noise around the mean
+		// plus the uncertainty of the mean itself.
+		sd := math.Sqrt(m.Dispersion*mean + mean*mean*variance)
+		if math.IsNaN(sd) || math.IsInf(sd, 0) {
+			return stores.Forecast{}, fmt.Errorf("forecast %s to %s: %w", from.Format(series.Layout), end.Format(series.Layout), growth.ErrNoFit)
+		}
+		date := end.AddDate(0, 0, h)
+		fc.Points = append(fc.Points, stores.ForecastPoint{
+			Date:    &date,
+			Horizon: h,
+			Mean:    mean,
+			Lower:   math.Max(0, mean-z95*sd),
+			Upper:   mean + z95*sd,
+		})
+	}
+	return fc, nil
+}
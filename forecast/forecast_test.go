@@ -0,0 +1,63 @@
+package forecast
+
+import (
+	"covidstats/growth"
+	"covidstats/stores/storestest"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestForecast(t *testing.T) {
+	// Cases doubling every 14 days, with some noise.
+	noise := []int{3, -5, 2, 0, -1, 4, -3}
+	var values []int
+	for i := 0; i < 28; i++ {
+		values = append(values, int(math.Round(100*math.Pow(2, float64(i)/14)))+noise[i%7])
+	}
+	end, _ := time.Parse("2006-01-02", "2021-12-28")
+	fc, err := Forecast(storestest.Counts("2021-12-01", values...), end, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Points) != 21 {
+		t.Fatalf("expected 21 days, got %d", len(fc.Points))
+	}
+	if fc.FittedFrom.Format("2006-01-02") != "2021-12-01" {
+		t.Errorf("expected the fit to start on 2021-12-01, got %s", fc.FittedFrom)
+	}
+
+	p14 := fc.Points[13]
+	if p14.Horizon != 14 || p14.Date.Format("2006-01-02") != "2022-01-11" {
+		t.Errorf("unexpected 14 day point %+v", p14)
+	}
+	// 41 days after the first fitted day cases are about 100 * 2^(41/14).
+	want := 100 * math.Pow(2, 41.0/14)
+	if math.Abs(p14.Mean-want)/want > 0.05 {
+		t.Errorf("expected a mean close to %f, got %f", want, p14.Mean)
+	}
+	if !(p14.Lower < p14.Mean && p14.Mean < p14.Upper) {
+		t.Errorf("expected the mean inside its interval, got %+v", p14)
+	}
+	p7, p21 := fc.Points[6], fc.Points[20]
+	if p21.Upper-p21.Lower <= p7.Upper-p7.Lower {
+		t.Errorf("expected the interval to widen with the horizon, got %+v and %+v", p7, p21)
+	}
+}
+
+func TestForecast_Errors(t *testing.T) {
+	end, _ := time.Parse("2006-01-02", "2021-12-28")
+	if _, err := Forecast(nil, end, DefaultConfig()); !errors.Is(err, ErrNoCases) {
+		t.Errorf("expected ErrNoCases, got %v", err)
+	}
+	if _, err := Forecast(nil, end, Config{Window: 3, Horizon: 7}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+	// A single case on the last fitted day cannot be extrapolated.
+	values := make([]int, 28)
+	values[27] = 1
+	if _, err := Forecast(storestest.Counts("2021-12-01", values...), end, DefaultConfig()); !errors.Is(err, growth.ErrNoFit) {
+		t.Errorf("expected ErrNoFit, got %v", err)
+	}
+}
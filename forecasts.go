@@ -0,0 +1,46 @@
+package covidstats
+
+import (
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleForecasts is the handler that returns the forecasts made by the sync
+// runs of the requested date range, each with its run date. It defaults to
+// the runs of the last 28 days.
+func (s *Server) HandleForecasts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleForecasts")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	from, to, rangeErr := parseRange(r, 28)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	forecasts, findErr := s.forecasts.FindByDateRange(r.Context(), from, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if forecasts == nil {
+		forecasts = []stores.Forecast{}
+	}
+	if err := json.NewEncoder(w).Encode(forecasts); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
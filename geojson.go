@@ -0,0 +1,101 @@
+package covidstats
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type featureCollection struct {
+	Type     string    `json:"type"`
+	Features []feature `json:"features"`
+}
+
+type feature struct {
+	Type       string          `json:"type"`
+	Properties districtFeature `json:"properties"`
+	Geometry   json.RawMessage `json:"geometry"`
+}
+
+// districtFeature are the properties of a district feature. The keys are
+// kept stable so map styles can reference them directly.
+type districtFeature struct {
+	District     string   `json:"district"`
+	From         string   `json:"from"`
+	To           string   `json:"to"`
+	Cases        int      `json:"cases"`
+	Population   int      `json:"population,omitempty"`
+	CasesPer100k *float64 `json:"casesPer100k,omitempty"`
+}
+
+// HandleDistrictGeoJSON is the handler that returns the district boundaries
+// as a GeoJSON FeatureCollection with the cases reported in each district
+// for the requested date range.
+func (s *Server) HandleDistrictGeoJSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/geo+json")
+	s.logger.Info("HandleDistrictGeoJSON")
+	if r.Method == http.MethodOptions {
+		return
+	}
+	if s.boundaries == nil {
+		s.logger.Error("district boundaries have not been configured")
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	from, to, rangeErr := parseRange(r, 14)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cases, findErr := s.casesService.FindByDateRange(r.Context(), from, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	totals := map[string]int{}
+	for _, c := range cases {
+		for d, n := range c.Districts {
+			totals[d] += n
+		}
+	}
+
+	fc := featureCollection{Type: "FeatureCollection"}
+	for _, d := range stores.Districts() {
+		props := districtFeature{
+			District: string(d),
+			From:     from.Format(series.Layout),
+			To:       to.Format(series.Layout),
+			Cases:    totals[string(d)],
+		}
+		if s.population != nil {
+			if pop, err := s.population.For(string(d), to.Year()); err == nil {
+				rate := series.Per100k(props.Cases, pop)
+				props.Population = pop
+				props.CasesPer100k = &rate
+			}
+		}
+		fc.Features = append(fc.Features, feature{
+			Type:       "Feature",
+			Properties: props,
+			Geometry:   s.boundaries.Geometry(d),
+		})
+	}
+
+	if err := json.NewEncoder(w).Encode(fc); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,80 @@
+package covidstats
+
+import (
+	"covidstats/growth"
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// growthStats is the growth rate of the country and of each district
+type growthStats struct {
+	National  growth.Estimate            `json:"national"`
+	Districts map[string]growth.Estimate `json:"districts"`
+}
+
+// HandleGrowthRate is the handler that returns the daily growth rate, with
+// the doubling or halving time, of the cases over the window days ending on
+// the to date, nationally and per district.
+func (s *Server) HandleGrowthRate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleGrowthRate")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	to, toErr := parseDate(q.Get("to"), time.Now().UTC().Truncate(24*time.Hour))
+	if toErr != nil {
+		http.Error(w, "to must be a date (yyyy-mm-dd)", http.StatusBadRequest)
+		return
+	}
+	cfg := growth.DefaultConfig()
+	if value := q.Get("window"); value != "" {
+		window, err := strconv.Atoi(value)
+		if err != nil || window < 7 || window > 56 {
+			http.Error(w, "window must be between 7 and 56 days", http.StatusBadRequest)
+			return
+		}
+		cfg.Window = window
+	}
+
+	cases, findErr := s.casesService.FindByDateRange(r.Context(), to.AddDate(0, 0, -(cfg.Window-1)), to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"to":     to,
+			"window": cfg.Window,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	national, fitErr := growth.Fit(stores.UnitCounts(cases, ""), to, cfg)
+	if fitErr != nil {
+		s.logger.WithError(fitErr).Error("growth.Fit failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	result := growthStats{National: national, Districts: map[string]growth.Estimate{}}
+	for _, d := range stores.Districts() {
+		est, err := growth.Fit(stores.UnitCounts(cases, string(d)), to, cfg)
+		if err != nil {
+			s.logger.WithField("district", d).WithError(err).Error("growth.Fit failed")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		result.Districts[string(d)] = est
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,228 @@
+// Package growth estimates the exponential growth rate of a daily case series
+// by fitting a quasi-Poisson log-linear model to a recent window.
+package growth
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidConfig is returned when the fit parameters are unusable
+var ErrInvalidConfig = errors.New("invalid growth config")
+
+// ErrNoFit is returned when the counts are too sparse for the model to
+// converge, such as when every case falls on the first or last day
+var ErrNoFit = errors.New("log-linear fit did not converge")
+
+const (
+	// z95 is the standard normal quantile of the 95% confidence interval
+	z95           = 1.959964
+	fitIterations = 50
+	fitTolerance  = 1e-10
+)
+
+// Config holds the parameters of the fit
+type Config struct {
+	// Window is the number of days, ending on the last day, that are fitted
+	Window int
+	// MinCases is the number of cases in the window below which the
+	// estimate is flagged as unreliable
+	MinCases int
+}
+
+// DefaultConfig returns a config fitting the last 14 days and warning below
+// 50 cases
+func DefaultConfig() Config {
+	return Config{Window: 14, MinCases: 50}
+}
+
+// Duration is a doubling or halving time in days with its 95% confidence
+// interval. Upper is nil when the interval of the rate reaches zero, as the
+// time is then unbounded.
+type Duration struct {
+	Days  float64  `json:"days"`
+	Lower float64  `json:"lower"`
+	Upper *float64 `json:"upper"`
+}
+
+// Estimate is the growth rate fitted over the window from From to To
+type Estimate struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	Cases int       `json:"cases"`
+	// Rate is the daily growth rate of the log cases, with its 95%
+	// confidence interval. They are nil when no rate could be fitted.
+	Rate  *float64 `json:"rate"`
+	Lower *float64 `json:"lower"`
+	Upper *float64 `json:"upper"`
+	// Dispersion is the quasi-Poisson dispersion the interval is scaled by
+	Dispersion float64 `json:"dispersion"`
+	// DoublingTime is set when the cases grow, HalvingTime when they
+	// decline
+	DoublingTime *Duration `json:"doublingTime"`
+	HalvingTime  *Duration `json:"halvingTime"`
+	// Warnings explain why the estimate may be unreliable
+	Warnings []string `json:"warnings"`
+}
+
+// Fit estimates the growth rate over the window of days ending on, and
+// including, end. Days missing from the series count as zero cases. When the
+// cases of the window are too few or too sparse no rate can be fitted and
+// only the warning is set.
+func Fit(counts []stores.CaseCount, end time.Time, cfg Config) (Estimate, error) {
+	if cfg.Window < 3 {
+		return Estimate{}, fmt.Errorf("%w: window must be at least 3 days", ErrInvalidConfig)
+	}
+
+	daily := series.Daily{}
+	for _, c := range counts {
+		if c.ReportingDate != nil {
+			daily.Add(*c.ReportingDate, c.Count)
+		}
+	}
+	est := Estimate{
+		From:     end.AddDate(0, 0, -(cfg.Window - 1)),
+		To:       end,
+		Cases:    daily.Sum(end, cfg.Window),
+		Warnings: []string{},
+	}
+	if est.Cases == 0 {
+		est.Warnings = append(est.Warnings, "no cases in the window")
+		return est, nil
+	}
+	if est.Cases < cfg.MinCases {
+		est.Warnings = append(est.Warnings,
+			fmt.Sprintf("only %d cases in the window, at least %d are needed for a reliable estimate", est.Cases, cfg.MinCases))
+	}
+
+	y := make([]float64, cfg.Window)
+	zeros := 0
+	for i := range y {
+		y[i] = float64(daily.Get(est.From.AddDate(0, 0, i)))
+		if y[i] == 0 {
+			zeros++
+		}
+	}
+	if zeros*2 > cfg.Window {
+		est.Warnings = append(est.Warnings, fmt.Sprintf("%d of %d days have no cases", zeros, cfg.Window))
+	}
+
+	m, err := FitLogLinear(y)
+	if errors.Is(err, ErrNoFit) {
+		est.Warnings = append(est.Warnings, "the cases are too sparse to fit a growth rate")
+		return est, nil
+	}
+	if err != nil {
+		return est, err
+	}
+	se := math.Sqrt(m.Cov[1][1])
+	rate, lower, upper := m.Slope, m.Slope-z95*se, m.Slope+z95*se
+	est.Rate, est.Lower, est.Upper = &rate, &lower, &upper
+	est.Dispersion = m.Dispersion
+	switch {
+	case rate > 0:
+		est.DoublingTime = duration(rate, lower, upper)
+	case rate < 0:
+		est.HalvingTime = duration(-rate, -upper, -lower)
+	}
+	return est, nil
+}
+
+// duration converts a positive rate and its interval into the time for the
+// cases to change by a factor of two
+func duration(rate, lower, upper float64) *Duration {
+	d := &Duration{Days: math.Ln2 / rate, Lower: math.Ln2 / upper}
+	if lower > 0 {
+		u := math.Ln2 / lower
+		d.Upper = &u
+	}
+	return d
+}
+
+// LogLinear is a quasi-Poisson log-linear model of daily counts,
+// log E[y] = Intercept + Slope*x, with x the day counted from the middle of
+// the fitted days
+type LogLinear struct {
+	Intercept float64
+	Slope     float64
+	// Centre is the index of the fitted day x is counted from
+	Centre float64
+	// Cov is the covariance of the intercept and slope, scaled by the
+	// dispersion
+	Cov [2][2]float64
+	// Dispersion is the ratio of the variance to the mean, never taken
+	// below one
+	Dispersion float64
+}
+
+// FitLogLinear fits the model to the counts of consecutive days by
+// iteratively reweighted least squares. The counts must not all be zero. It
+// returns ErrNoFit when the fit does not converge to finite estimates.
+func FitLogLinear(y []float64) (LogLinear, error) {
+	n := len(y)
+	m := LogLinear{Centre: float64(n-1) / 2}
+	x := make([]float64, n)
+	mean := 0.0
+	for i := range y {
+		x[i] = float64(i) - m.Centre
+		mean += y[i]
+	}
+	a, b := math.Log(mean/float64(n)), 0.0
+
+	var s0, s1, s2 float64
+	converged := false
+	for iter := 0; iter < fitIterations && !converged; iter++ {
+		var t0, t1 float64
+		s0, s1, s2 = 0, 0, 0
+		for i := range y {
+			eta := a + b*x[i]
+			mu := math.Exp(eta)
+			z := eta + (y[i]-mu)/mu
+			s0 += mu
+			s1 += mu * x[i]
+			s2 += mu * x[i] * x[i]
+			t0 += mu * z
+			t1 += mu * x[i] * z
+		}
+		det := s0*s2 - s1*s1
+		nextA := (s2*t0 - s1*t1) / det
+		nextB := (s0*t1 - s1*t0) / det
+		converged = math.Abs(nextA-a) < fitTolerance && math.Abs(nextB-b) < fitTolerance
+		a, b = nextA, nextB
+	}
+	if !converged || math.IsNaN(a) || math.IsNaN(b) {
+		return m, ErrNoFit
+	}
+	m.Intercept, m.Slope = a, b
+
+	pearson := 0.0
+	for i := range y {
+		mu := math.Exp(a + b*x[i])
+		pearson += (y[i] - mu) * (y[i] - mu) / mu
+	}
+	m.Dispersion = math.Max(1, pearson/float64(n-2))
+	det := s0*s2 - s1*s1
+	m.Cov = [2][2]float64{
+		{m.Dispersion * s2 / det, -m.Dispersion * s1 / det},
+		{-m.Dispersion * s1 / det, m.Dispersion * s0 / det},
+	}
+	for _, v := range []float64{m.Dispersion, m.Cov[0][0], m.Cov[0][1], m.Cov[1][1]} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return m, ErrNoFit
+		}
+	}
+	return m, nil
+}
+
+// Predict returns the log of the expected count on day i, counted from the
+// first fitted day, and its variance
+func (m LogLinear) Predict(i float64) (eta, variance float64) {
+	x := i - m.Centre
+	eta = m.Intercept + m.Slope*x
+	variance = m.Cov[0][0] + 2*x*m.Cov[0][1] + x*x*m.Cov[1][1]
+	return eta, variance
+}
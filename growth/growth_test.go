@@ -0,0 +1,107 @@
+package growth
+
+import (
+	"covidstats/stores/storestest"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFit_Growth(t *testing.T) {
+	// Cases doubling every 7 days, across the new year.
+	var values []int
+	for i := 0; i < 14; i++ {
+		values = append(values, int(math.Round(100*math.Pow(2, float64(i)/7))))
+	}
+	end, _ := time.Parse("2006-01-02", "2021-01-06")
+	est, err := Fit(storestest.Counts("2020-12-24", values...), end, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.From.Format("2006-01-02") != "2020-12-24" {
+		t.Errorf("expected the window to start on 2020-12-24, got %s", est.From)
+	}
+	if est.Rate == nil || math.Abs(*est.Rate-math.Ln2/7) > 0.001 {
+		t.Errorf("expected rate %f, got %v", math.Ln2/7, est.Rate)
+	}
+	if est.DoublingTime == nil || math.Abs(est.DoublingTime.Days-7) > 0.1 {
+		t.Fatalf("expected doubling time of 7 days, got %+v", est.DoublingTime)
+	}
+	if est.DoublingTime.Upper == nil || *est.DoublingTime.Upper < 7 || est.DoublingTime.Lower > 7 {
+		t.Errorf("expected the interval to contain 7 days, got %+v", est.DoublingTime)
+	}
+	if est.HalvingTime != nil {
+		t.Errorf("expected no halving time, got %+v", est.HalvingTime)
+	}
+	if len(est.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", est.Warnings)
+	}
+}
+
+func TestFit_Decline(t *testing.T) {
+	var values []int
+	for i := 0; i < 14; i++ {
+		values = append(values, int(math.Round(400*math.Pow(0.5, float64(i)/10))))
+	}
+	end, _ := time.Parse("2006-01-02", "2021-03-14")
+	est, err := Fit(storestest.Counts("2021-03-01", values...), end, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.HalvingTime == nil || math.Abs(est.HalvingTime.Days-10) > 0.2 {
+		t.Errorf("expected halving time of 10 days, got %+v", est.HalvingTime)
+	}
+	if est.DoublingTime != nil {
+		t.Errorf("expected no doubling time, got %+v", est.DoublingTime)
+	}
+}
+
+func TestFit_LowCounts(t *testing.T) {
+	end, _ := time.Parse("2006-01-02", "2021-03-14")
+	est, err := Fit(storestest.Counts("2021-03-01", 0, 1, 0, 0, 2, 0, 0, 0, 1, 0, 0, 3, 0, 1), end, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.Cases != 8 || len(est.Warnings) != 2 {
+		t.Errorf("expected 8 cases with 2 warnings, got %d cases and %v", est.Cases, est.Warnings)
+	}
+
+	est, err = Fit(nil, end, DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.Rate != nil || len(est.Warnings) != 1 {
+		t.Errorf("expected only a warning without cases, got %+v", est)
+	}
+}
+
+func TestFit_CasesOnEdgeDay(t *testing.T) {
+	end, _ := time.Parse("2006-01-02", "2021-03-14")
+	for _, edge := range []int{0, 13} {
+		values := make([]int, 14)
+		values[edge] = 1
+		est, err := Fit(storestest.Counts("2021-03-01", values...), end, DefaultConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if est.Rate != nil || est.Lower != nil || est.Upper != nil {
+			t.Errorf("expected no rate with a single case on day %d, got %+v", edge, est)
+		}
+		if len(est.Warnings) == 0 || est.Warnings[len(est.Warnings)-1] != "the cases are too sparse to fit a growth rate" {
+			t.Errorf("expected a sparse cases warning with a single case on day %d, got %v", edge, est.Warnings)
+		}
+	}
+}
+
+func TestFitLogLinear_NoFit(t *testing.T) {
+	if _, err := FitLogLinear([]float64{3, 0, 0, 0, 0, 0, 0}); !errors.Is(err, ErrNoFit) {
+		t.Errorf("expected ErrNoFit, got %v", err)
+	}
+}
+
+func TestFit_InvalidConfig(t *testing.T) {
+	if _, err := Fit(nil, time.Now(), Config{Window: 2}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
@@ -1,7 +1,12 @@
 package covidstats
 
 import (
+	"context"
+	"covidstats/series"
+	"covidstats/stores"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,8 +16,115 @@ import (
 	"github.com/gorilla/mux"
 )
 
+// errInvalidParam is returned for query parameters with an invalid value
+var errInvalidParam = errors.New("invalid query parameter")
+
+// parseDate parses a yyyy-mm-dd query value, returning def when it is empty
+func parseDate(value string, def time.Time) (time.Time, error) {
+	if value == "" {
+		return def, nil
+	}
+	return time.Parse(series.Layout, value)
+}
+
+// maxRangeDays bounds the days a from/to range can span
+const maxRangeDays = 3660
+
+// parseRange reads the from and to query parameters. to defaults to today and
+// from to the start of a range of defaultDays days ending on to. Both are
+// inclusive.
+func parseRange(r *http.Request, defaultDays int) (from, to time.Time, err error) {
+	q := r.URL.Query()
+	to, toErr := parseDate(q.Get("to"), time.Now().UTC().Truncate(24*time.Hour))
+	from, fromErr := parseDate(q.Get("from"), to.AddDate(0, 0, -(defaultDays-1)))
+	if toErr != nil || fromErr != nil {
+		return from, to, fmt.Errorf("%w: from and to must be dates (yyyy-mm-dd) with from before to", errInvalidParam)
+	}
+	return from, to, checkRange(from, to)
+}
+
+// checkRange checks from is not after to and the range spans at most
+// maxRangeDays days
+func checkRange(from, to time.Time) error {
+	if from.After(to) {
+		return fmt.Errorf("%w: from and to must be dates (yyyy-mm-dd) with from before to", errInvalidParam)
+	}
+	if to.Sub(from) >= maxRangeDays*24*time.Hour {
+		return fmt.Errorf("%w: from and to must be at most %d days apart", errInvalidParam, maxRangeDays)
+	}
+	return nil
+}
+
+// classificationView selects how the classification dimension of the cases
+// is returned. Only the case stats by year and month take it: the other
+// series, such as deaths, recoveries and onsets, are stored for the published
+// classifications alone, and the daily, cumulative and modelled series are
+// defined over the published count.
+type classificationView struct {
+	// filter holds the classification names that make up the count. When
+	// empty the published count is kept.
+	filter []string
+	// split keeps the count of each classification in the response
+	split bool
+}
+
+// parseClassificationView reads the classification and split query
+// parameters
+func parseClassificationView(r *http.Request) (classificationView, error) {
+	var v classificationView
+	q := r.URL.Query()
+	if c := q.Get("classification"); c != "" {
+		ids, err := stores.ParseClassifications(c)
+		if err != nil {
+			return v, err //nolint:wrapcheck
+		}
+		for _, id := range ids {
+			v.filter = append(v.filter, stores.ClassificationName(id))
+		}
+	}
+	switch q.Get("split") {
+	case "":
+	case "classification":
+		v.split = true
+	default:
+		return v, fmt.Errorf("%w: split=%s", errInvalidParam, q.Get("split"))
+	}
+	return v, nil
+}
+
+// apply returns the cases as selected by the view. Filtered counts have no
+// district breakdown since districts only hold the published count.
+func (v classificationView) apply(cases []stores.CasesCountByDate) []stores.CasesCountByDate {
+	result := make([]stores.CasesCountByDate, 0, len(cases))
+	for _, c := range cases {
+		if len(v.filter) > 0 {
+			c.Count = 0
+			for _, name := range v.filter {
+				c.Count += c.Classifications[name]
+			}
+			c.Districts = nil
+		}
+		if !v.split {
+			c.Classifications = nil
+		}
+		result = append(result, c)
+	}
+	return result
+}
+
+// share returns part as a fraction of whole, or nil when whole is zero
+func share(part, whole int) *float64 {
+	if whole == 0 {
+		return nil
+	}
+	f := float64(part) / float64(whole)
+	return &f
+}
+
 // HandleFindYearStats is the handler that returns the confirmed cases
-// grouped by date for a given year.
+// grouped by date for a given year. The classification query parameter
+// counts other classifications, and split=classification adds the count of
+// each classification.
 func (s *Server) HandleFindYearStats(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 	s.logger.Info("HandleFindYearStats")
@@ -20,6 +132,12 @@ func (s *Server) HandleFindYearStats(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	view, viewErr := parseClassificationView(r)
+	if viewErr != nil {
+		http.Error(w, viewErr.Error(), http.StatusBadRequest)
+		return
+	}
+
 	vars := mux.Vars(r)
 	yr := vars["year"]
 	year, _ := strconv.Atoi(yr)
@@ -39,6 +157,189 @@ func (s *Server) HandleFindYearStats(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	s.writeCaseStats(w, r, view, cases, jan1)
+}
+
+// HandleFindMonthStats is the handler that returns the confirmed cases
+// grouped by date for a given month (yyyy-mm). It takes the same query
+// parameters as HandleFindYearStats.
+func (s *Server) HandleFindMonthStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleFindMonthStats")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	view, viewErr := parseClassificationView(r)
+	if viewErr != nil {
+		http.Error(w, viewErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	first, monthErr := parseMonth(vars["month"])
+	if monthErr != nil {
+		http.Error(w, monthErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cases, findErr := s.casesService.FindByMonth(r.Context(), vars["month"])
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"month": vars["month"],
+			"vars":  vars,
+		}).
+			WithError(findErr).
+			Error("FindByMonth failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	s.writeCaseStats(w, r, view, cases, first)
+}
+
+// parseMonth parses a month in the yyyy-mm format the stats store keys
+// months with, returning its first day
+func parseMonth(value string) (time.Time, error) {
+	p, err := series.ParsePeriod(value)
+	if err != nil || p.Kind != series.PeriodMonth {
+		return time.Time{}, fmt.Errorf("%w: month must be yyyy-mm, got %q", errInvalidParam, value)
+	}
+	return p.From, nil
+}
+
+// parseEpiWeek parses an epidemiological week route variable, yyyy-w or
+// yyyy-ww, returning the Sunday it starts on
+func parseEpiWeek(value string) (time.Time, error) {
+	start, err := series.ParseEpiWeek(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: epiWeek must be a week of the year (yyyy-w), got %q", errInvalidParam, value)
+	}
+	return start, nil
+}
+
+// parseWeek parses an ISO week route variable, yyyy-w or yyyy-ww, returning
+// the week the way the statistics are keyed (yyyy-w)
+func parseWeek(value string) (string, error) {
+	start, err := series.ParseISOWeek(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: week must be a week of the year (yyyy-w), got %q", errInvalidParam, value)
+	}
+	week, _, _ := stores.ISOWeeks.Of(start)
+	return week, nil
+}
+
+// weeklyQuery is the year or week the route of a weekly statistic asks for.
+// epi is set for the routes of the epidemiological weeks.
+type weeklyQuery struct {
+	year int
+	week string
+	epi  bool
+}
+
+// parseWeeklyQuery reads the year, week, epiYear or epiWeek route variable.
+// Weeks are returned as the yyyy-w they are stored with.
+func parseWeeklyQuery(vars map[string]string) (weeklyQuery, error) {
+	switch {
+	case vars["epiWeek"] != "":
+		start, err := parseEpiWeek(vars["epiWeek"])
+		if err != nil {
+			return weeklyQuery{}, err
+		}
+		return weeklyQuery{week: series.EpiWeekKey(start), epi: true}, nil
+	case vars["epiYear"] != "":
+		year, _ := strconv.Atoi(vars["epiYear"])
+		return weeklyQuery{year: year, epi: true}, nil
+	case vars["year"] != "":
+		year, _ := strconv.Atoi(vars["year"])
+		return weeklyQuery{year: year}, nil
+	}
+	week, err := parseWeek(vars["week"])
+	if err != nil {
+		return weeklyQuery{}, err
+	}
+	return weeklyQuery{week: week}, nil
+}
+
+// writeCaseStats writes the cases of the period starting on start with their
+// incidence, fetching the days before the period the incidence windows reach
+func (s *Server) writeCaseStats(w http.ResponseWriter, r *http.Request, view classificationView, cases []stores.CasesCountByDate, start time.Time) {
+	leadIn, findErr := s.casesService.FindByDateRange(r.Context(), start.AddDate(0, 0, -incidenceLeadIn), start)
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"start": start,
+			"vars":  mux.Vars(r),
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if s.population != nil {
+		w.Header().Set("X-Population-Version", s.population.Version)
+	}
+	if err := json.NewEncoder(w).Encode(s.withIncidence(view.apply(cases), view.apply(leadIn))); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
+
+// statsFinder retrieves persisted daily counts by year, month, ISO week or
+// epidemiological week
+type statsFinder interface {
+	FindByYear(ctx context.Context, year int) ([]stores.CasesCountByDate, error)
+	FindByMonth(ctx context.Context, month string) ([]stores.CasesCountByDate, error)
+	FindByWeek(ctx context.Context, week string) ([]stores.CasesCountByDate, error)
+	FindByEpiWeek(ctx context.Context, start time.Time) ([]stores.CasesCountByDate, error)
+}
+
+// findStats writes the daily counts for the year, month, week or epiWeek in
+// the route variables. Epidemiological weeks can be written as yyyy-w or
+// yyyy-ww.
+func (s *Server) findStats(w http.ResponseWriter, r *http.Request, svc statsFinder) {
+	vars := mux.Vars(r)
+	var (
+		cases   []stores.CasesCountByDate
+		findErr error
+	)
+	switch {
+	case vars["year"] != "":
+		year, _ := strconv.Atoi(vars["year"])
+		cases, findErr = svc.FindByYear(r.Context(), year)
+	case vars["month"] != "":
+		if _, err := parseMonth(vars["month"]); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cases, findErr = svc.FindByMonth(r.Context(), vars["month"])
+	case vars["epiWeek"] != "":
+		start, err := parseEpiWeek(vars["epiWeek"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cases, findErr = svc.FindByEpiWeek(r.Context(), start)
+	default:
+		week, err := parseWeek(vars["week"])
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cases, findErr = svc.FindByWeek(r.Context(), week)
+	}
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"vars": vars,
+		}).
+			WithError(findErr).
+			Error("finding stats failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if cases == nil {
+		cases = []stores.CasesCountByDate{}
+	}
 	if err := json.NewEncoder(w).Encode(cases); err != nil {
 		s.logger.WithError(err).Error("encoding json response failed")
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
@@ -0,0 +1,45 @@
+package covidstats
+
+import (
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// HandleHospitalStats is the handler that returns the daily hospital and ICU
+// admissions and occupancy for the requested date range.
+func (s *Server) HandleHospitalStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleHospitalStats")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	counts, findErr := s.hospital.FindByDateRange(r.Context(), from, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if counts == nil {
+		counts = []stores.HospitalCount{}
+	}
+	if err := json.NewEncoder(w).Encode(counts); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,84 @@
+package covidstats
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"time"
+)
+
+// incidenceLeadIn is how many days before the first requested day are
+// needed to compute the 14 day window.
+const incidenceLeadIn = 13
+
+// incidence holds the cases per 100,000 population
+type incidence struct {
+	Daily       float64 `json:"daily"`
+	SevenDay    float64 `json:"sevenDay"`
+	FourteenDay float64 `json:"fourteenDay"`
+}
+
+// caseStats are the persisted case counts along with their incidence rates
+type caseStats struct {
+	stores.CasesCountByDate
+	Incidence         *incidence           `json:"incidencePer100k,omitempty"`
+	DistrictIncidence map[string]incidence `json:"districtIncidencePer100k,omitempty"`
+}
+
+func newIncidence(daily series.Daily, day time.Time, population int) incidence {
+	return incidence{
+		Daily:       series.Per100k(daily.Get(day), population),
+		SevenDay:    series.Per100k(daily.Sum(day, 7), population),
+		FourteenDay: series.Per100k(daily.Sum(day, 14), population),
+	}
+}
+
+// withIncidence adds the incidence rates to the cases. leadIn holds the cases
+// reported in the days before the first case so windows can be computed.
+// The rates are left out when no population has been configured.
+func (s *Server) withIncidence(cases, leadIn []stores.CasesCountByDate) []caseStats {
+	stats := make([]caseStats, 0, len(cases))
+	for _, c := range cases {
+		stats = append(stats, caseStats{CasesCountByDate: c})
+	}
+	if s.population == nil {
+		return stats
+	}
+
+	national := series.Daily{}
+	districts := map[string]series.Daily{}
+	for _, c := range append(append([]stores.CasesCountByDate{}, leadIn...), cases...) {
+		if c.ReportingDate == nil {
+			continue
+		}
+		national.Add(*c.ReportingDate, c.Count)
+		for d, n := range c.Districts {
+			if districts[d] == nil {
+				districts[d] = series.Daily{}
+			}
+			districts[d].Add(*c.ReportingDate, n)
+		}
+	}
+
+	for i, c := range stats {
+		if c.ReportingDate == nil {
+			continue
+		}
+		day := *c.ReportingDate
+		if pop, err := s.population.National(day.Year()); err == nil {
+			inc := newIncidence(national, day, pop)
+			stats[i].Incidence = &inc
+		}
+		if len(c.Districts) == 0 {
+			continue
+		}
+		stats[i].DistrictIncidence = map[string]incidence{}
+		for d := range c.Districts {
+			pop, err := s.population.For(d, day.Year())
+			if err != nil {
+				continue
+			}
+			stats[i].DistrictIncidence[d] = newIncidence(districts[d], day, pop)
+		}
+	}
+	return stats
+}
@@ -0,0 +1,87 @@
+package covidstats
+
+import (
+	"covidstats/nowcast"
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// nowcastHistory is the number of days of sync runs the completeness of the
+// counts is learnt from
+const nowcastHistory = 120
+
+// nowcastStats is the nowcast of the cases as of the latest sync run
+type nowcastStats struct {
+	AsOf     time.Time     `json:"asOf"`
+	MaxDelay int           `json:"maxDelay"`
+	Days     []nowcast.Day `json:"days"`
+}
+
+// HandleNowcast is the handler that returns the observed cases of the
+// requested date range along with their nowcast. The days query parameter
+// sets how many of the most recent days are still incomplete, and so adjusted.
+func (s *Server) HandleNowcast(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleNowcast")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	from, to, rangeErr := parseRange(r, 60)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+	maxDelay := nowcast.DefaultMaxDelay
+	if value := q.Get("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 1 || days > 28 {
+			http.Error(w, "days must be between 1 and 28", http.StatusBadRequest)
+			return
+		}
+		maxDelay = days
+	}
+
+	snapshots, snapErr := s.snapshots.FindSince(r.Context(), today.AddDate(0, 0, -nowcastHistory))
+	if snapErr != nil {
+		s.logger.WithError(snapErr).Error("FindSince failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	cases, findErr := s.casesService.FindByDateRange(r.Context(), from, to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// The stored counts are those of the latest run.
+	asOf := today
+	if len(snapshots) > 0 {
+		asOf = *snapshots[len(snapshots)-1].RunDate
+	}
+	completeness := nowcast.Learn(snapshots, maxDelay)
+	result := nowcastStats{
+		AsOf:     asOf,
+		MaxDelay: maxDelay,
+		Days:     nowcast.Adjust(stores.UnitCounts(cases, ""), from, to.AddDate(0, 0, 1), asOf, completeness),
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
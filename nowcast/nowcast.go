@@ -0,0 +1,156 @@
+// Package nowcast corrects the most recent days of the case series for the
+// cases that are still to be reported against them.
+//
+// Every sync run keeps a snapshot of the count of each reporting date. A
+// reporting date is settled once the latest run is MaxDelay or more days past
+// it, and its count in the latest snapshot is taken as final. The share of the
+// final count an earlier run had seen, d days after the reporting date, gives
+// the completeness of the counts at a delay of d days. The counts of the last
+// MaxDelay days are divided by their completeness, with bands from the spread
+// of the completeness across the settled dates.
+package nowcast
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"sort"
+	"time"
+)
+
+// DefaultMaxDelay is the number of days after which the counts of a
+// reporting date are considered complete
+const DefaultMaxDelay = 14
+
+// Completeness is the share of the final count seen at each delay
+type Completeness struct {
+	// Share is indexed by the delay in days. It is zero for delays no
+	// settled date was seen at.
+	Share []float64
+	// Lower and Upper are the 2.5% and 97.5% quantiles of the share seen
+	// by the individual settled dates
+	Lower []float64
+	Upper []float64
+	// Dates is the number of settled dates seen at each delay
+	Dates []int
+}
+
+// Learn estimates the completeness at each delay below maxDelay from the
+// snapshots of the sync runs
+func Learn(snapshots []stores.Snapshot, maxDelay int) Completeness {
+	c := Completeness{
+		Share: make([]float64, maxDelay),
+		Lower: make([]float64, maxDelay),
+		Upper: make([]float64, maxDelay),
+		Dates: make([]int, maxDelay),
+	}
+	latest := latestSnapshot(snapshots)
+	if latest == nil {
+		return c
+	}
+
+	seen := make([]float64, maxDelay)
+	final := make([]float64, maxDelay)
+	ratios := make([][]float64, maxDelay)
+	for _, snap := range snapshots {
+		if snap.RunDate == nil || snap.RunDate.Equal(*latest.RunDate) {
+			continue
+		}
+		for key, count := range snap.Counts {
+			day, err := time.Parse(series.Layout, key)
+			if err != nil || days(day, *latest.RunDate) < maxDelay {
+				continue
+			}
+			total, ok := latest.Counts[key]
+			delay := days(day, *snap.RunDate)
+			if !ok || total == 0 || delay < 0 || delay >= maxDelay {
+				continue
+			}
+			seen[delay] += float64(count)
+			final[delay] += float64(total)
+			ratios[delay] = append(ratios[delay], float64(count)/float64(total))
+		}
+	}
+
+	for d := range ratios {
+		if len(ratios[d]) == 0 {
+			continue
+		}
+		sort.Float64s(ratios[d])
+		c.Share[d] = seen[d] / final[d]
+		c.Lower[d] = series.Quantile(ratios[d], 0.025)
+		c.Upper[d] = series.Quantile(ratios[d], 0.975)
+		c.Dates[d] = len(ratios[d])
+	}
+	return c
+}
+
+// Day is the observed and nowcast count of a reporting date
+type Day struct {
+	Date     time.Time `json:"date"`
+	Observed int       `json:"observed"`
+	Nowcast  float64   `json:"nowcast"`
+	// Lower and Upper bound the nowcast. Upper is nil when some settled
+	// dates had no cases reported at this delay, as the count is then
+	// unbounded.
+	Lower float64  `json:"lower"`
+	Upper *float64 `json:"upper"`
+	// Delay is the number of days between the date and the latest run
+	Delay int `json:"delay"`
+	// Adjusted tells whether the nowcast differs from the observed count
+	Adjusted bool `json:"adjusted"`
+}
+
+// Adjust returns the nowcast of the observed counts from (inclusive) up to
+// (exclusive) to, as seen by the run of asOf. Days are zero filled. Days the
+// completeness is unknown for are left as observed.
+func Adjust(observed []stores.CaseCount, from, to, asOf time.Time, c Completeness) []Day {
+	daily := series.Daily{}
+	for _, o := range observed {
+		if o.ReportingDate != nil {
+			daily.Add(*o.ReportingDate, o.Count)
+		}
+	}
+
+	result := []Day{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		count := float64(daily.Get(day))
+		upper := count
+		d := Day{
+			Date:     day,
+			Observed: daily.Get(day),
+			Nowcast:  count,
+			Lower:    count,
+			Upper:    &upper,
+			Delay:    days(day, asOf),
+		}
+		if d.Delay >= 0 && d.Delay < len(c.Share) && c.Share[d.Delay] > 0 && c.Share[d.Delay] < 1 {
+			d.Nowcast = count / c.Share[d.Delay]
+			d.Lower = count / c.Upper[d.Delay]
+			if c.Lower[d.Delay] > 0 {
+				bound := count / c.Lower[d.Delay]
+				d.Upper = &bound
+			} else {
+				d.Upper = nil
+			}
+			d.Adjusted = true
+		}
+		result = append(result, d)
+	}
+	return result
+}
+
+// latestSnapshot returns the snapshot of the last run
+func latestSnapshot(snapshots []stores.Snapshot) *stores.Snapshot {
+	var latest *stores.Snapshot
+	for i, snap := range snapshots {
+		if snap.RunDate != nil && (latest == nil || snap.RunDate.After(*latest.RunDate)) {
+			latest = &snapshots[i]
+		}
+	}
+	return latest
+}
+
+// days returns the number of whole days from the day to the run
+func days(day, run time.Time) int {
+	return int(run.UTC().Truncate(24*time.Hour).Sub(day.UTC().Truncate(24*time.Hour)).Hours() / 24)
+}
@@ -0,0 +1,88 @@
+package nowcast
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"math"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse(series.Layout, s)
+	return d
+}
+
+// snapshot returns the snapshot of a run on the given day where every
+// reporting date has reached the share of its final count given for its delay
+func snapshot(run string, final int, shares ...float64) stores.Snapshot {
+	r := date(run)
+	counts := series.Daily{}
+	for day := r.AddDate(0, 0, -20); !day.After(r); day = day.AddDate(0, 0, 1) {
+		delay := days(day, r)
+		share := 1.0
+		if delay < len(shares) {
+			share = shares[delay]
+		}
+		counts.Add(day, int(math.Round(float64(final)*share)))
+	}
+	return stores.Snapshot{RunDate: &r, Counts: counts}
+}
+
+func TestLearn(t *testing.T) {
+	var snapshots []stores.Snapshot
+	for day := date("2021-12-20"); !day.After(date("2022-01-20")); day = day.AddDate(0, 0, 1) {
+		snapshots = append(snapshots, snapshot(day.Format(series.Layout), 100, 0.4, 0.7, 0.9))
+	}
+	c := Learn(snapshots, 5)
+
+	for d, want := range []float64{0.4, 0.7, 0.9, 1, 1} {
+		if math.Abs(c.Share[d]-want) > 1e-9 {
+			t.Errorf("expected share %f at delay %d, got %f", want, d, c.Share[d])
+		}
+		if c.Dates[d] == 0 {
+			t.Errorf("expected settled dates at delay %d", d)
+		}
+	}
+}
+
+func TestLearn_NoSnapshots(t *testing.T) {
+	c := Learn(nil, 3)
+	if len(c.Share) != 3 || c.Share[0] != 0 {
+		t.Errorf("expected unknown completeness, got %+v", c)
+	}
+}
+
+func TestAdjust(t *testing.T) {
+	c := Completeness{
+		Share: []float64{0.5, 0.8, 1},
+		Lower: []float64{0, 0.75, 1},
+		Upper: []float64{0.6, 0.9, 1},
+		Dates: []int{10, 10, 10},
+	}
+	d := func(s string) *time.Time {
+		v := date(s)
+		return &v
+	}
+	observed := []stores.CaseCount{
+		{ReportingDate: d("2021-12-30"), Count: 30},
+		{ReportingDate: d("2021-12-31"), Count: 40},
+		{ReportingDate: d("2022-01-01"), Count: 10},
+	}
+	days := Adjust(observed, date("2021-12-29"), date("2022-01-02"), date("2022-01-01"), c)
+	if len(days) != 4 {
+		t.Fatalf("expected 4 days, got %d", len(days))
+	}
+	if days[0].Observed != 0 || days[0].Adjusted {
+		t.Errorf("expected an unadjusted zero filled day, got %+v", days[0])
+	}
+	if days[1].Adjusted || days[1].Nowcast != 30 {
+		t.Errorf("expected complete counts to be left as observed, got %+v", days[1])
+	}
+	if !days[2].Adjusted || days[2].Nowcast != 50 || days[2].Upper == nil || math.Abs(*days[2].Upper-40/0.75) > 1e-9 {
+		t.Errorf("unexpected nowcast for a delay of 1 day %+v", days[2])
+	}
+	if days[3].Nowcast != 20 || days[3].Upper != nil || math.Abs(days[3].Lower-10/0.6) > 1e-9 {
+		t.Errorf("unexpected nowcast for a delay of 0 days %+v", days[3])
+	}
+}
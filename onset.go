@@ -0,0 +1,72 @@
+package covidstats
+
+import (
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleFindOnset is the handler that returns the cases grouped by date of
+// onset for a given year, month, ISO week or epidemiological week. Cases
+// without a date of onset are reported by HandleReportingDelay.
+func (s *Server) HandleFindOnset(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleFindOnset")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	s.findStats(w, r, s.onsetService)
+}
+
+// HandleReportingDelay is the handler that returns the weekly distribution of
+// the delay from onset to reporting for a year or an ISO week, or for an
+// epidemiological year or week.
+func (s *Server) HandleReportingDelay(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleReportingDelay")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	vars := mux.Vars(r)
+	wq, queryErr := parseWeeklyQuery(vars)
+	if queryErr != nil {
+		http.Error(w, queryErr.Error(), http.StatusBadRequest)
+		return
+	}
+	svc := s.reportingDelays
+	if wq.epi {
+		svc = s.epiWeekReportingDelays
+	}
+	var (
+		delays  []stores.ReportingDelay
+		findErr error
+	)
+	if wq.week == "" {
+		delays, findErr = svc.FindByYear(r.Context(), wq.year)
+	} else {
+		delays, findErr = svc.FindByWeek(r.Context(), wq.week)
+	}
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"vars": vars,
+		}).
+			WithError(findErr).
+			Error("finding reporting delays failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if delays == nil {
+		delays = []stores.ReportingDelay{}
+	}
+	if err := json.NewEncoder(w).Encode(delays); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
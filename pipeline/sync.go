@@ -0,0 +1,519 @@
+// Package pipeline copies the case data from Go.Data into the stats store.
+package pipeline
+
+import (
+	"context"
+	"covidstats/aberration"
+	"covidstats/forecast"
+	"covidstats/growth"
+	"covidstats/stores"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sync reads cases from Go.Data and persists the aggregated statistics
+type Sync struct {
+	Source     *stores.Mongo
+	OutbreakID string
+	Cases      *stores.CasesByDateService
+	// Classifications are the case classifications tracked in the stats.
+	// Only the Published ones count toward the totals. Both default to
+	// confirmed cases.
+	Classifications []string
+	Published       []string
+	// Deaths is optional. When set the deaths are persisted by date of
+	// outcome.
+	Deaths *stores.CasesByDateService
+	// Recoveries is optional. When set the recoveries are persisted by
+	// date of recovery. Cases without an outcome recover once
+	// IsolationDays have passed since they were reported.
+	Recoveries    *stores.CasesByDateService
+	IsolationDays int
+	// Hospital is optional. When set the daily admissions and occupancy of
+	// each care setting in CareTypes are persisted.
+	Hospital  *stores.HospitalService
+	CareTypes map[string]string
+	// Tests is optional. When set the tests performed and positive lab
+	// results are persisted by test date.
+	Tests *stores.TestsService
+	// ContactTracing is optional. When set the daily contact tracing
+	// activity is persisted.
+	ContactTracing *stores.ContactTracingService
+	// VaccinationStatus is optional. When set the weekly breakdown of the
+	// cases and deaths by vaccination status is persisted.
+	VaccinationStatus *stores.VaccinationStatusService
+	VaccinationRule   stores.VaccinationRule
+	// Onset is optional. When set the cases are also persisted by date of
+	// onset.
+	Onset *stores.CasesByDateService
+	// ReportingDelays is optional. When set the weekly distribution of
+	// the delay from onset to reporting is persisted.
+	ReportingDelays *stores.ReportingDelayService
+	// Clusters is optional. When set the weekly cluster statistics of the
+	// whole outbreak are recomputed and persisted.
+	Clusters *stores.ClusterService
+	// EpiWeekVaccinationStatus, EpiWeekReportingDelays and EpiWeekClusters
+	// are optional. When set the weekly statistics are also persisted by
+	// MMWR epidemiological week.
+	EpiWeekVaccinationStatus *stores.VaccinationStatusService
+	EpiWeekReportingDelays   *stores.ReportingDelayService
+	EpiWeekClusters          *stores.ClusterService
+	// Demographics is optional. When set the age and sex breakdown of
+	// the cases is persisted, grouped by AgeBands.
+	Demographics *stores.DemographicsService
+	AgeBands     []stores.AgeBand
+	// Snapshots is optional. When set the counts of the run are kept so the
+	// backfilling of the reporting dates can be learnt for nowcasting.
+	Snapshots *stores.SnapshotService
+	// Forecasts is optional. When set the cases are forecast from the stored
+	// series once it is synced, with the Forecast settings.
+	Forecasts *stores.ForecastService
+	Forecast  forecast.Config
+	// Alerts is optional. When set the aberration detection methods are run
+	// on the synced days and the whole weeks among them, nationally and per
+	// district, with the Alert thresholds.
+	Alerts *stores.AlertService
+	Alert  aberration.Config
+	Logger *logrus.Logger
+}
+
+// Run synchronises the statistics for cases reported from (inclusive) up to
+// (exclusive) the given dates.
+func (s *Sync) Run(ctx context.Context, from, to time.Time) error {
+	log := s.Logger.WithFields(logrus.Fields{
+		"outbreakId": s.OutbreakID,
+		"from":       from.Format("2006-01-02"),
+		"to":         to.Format("2006-01-02"),
+	})
+
+	tracked, published := s.classifications()
+	cases, err := s.Source.FindCases(ctx, s.OutbreakID, tracked, from, &to)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	cases, err = s.Source.AddDistrictToCase(ctx, cases)
+	if err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	// Every day of the range is saved so days left without cases are cleared.
+	// Days still to come are left alone.
+	counts := stores.FillCaseCounts(stores.CountCasesByClassification(cases, tracked, published), tracked, from, untilToday(to))
+	if len(counts) == 0 {
+		log.Info("no cases to sync")
+	} else {
+		if err := s.Cases.Save(ctx, counts); err != nil {
+			return fmt.Errorf("sync: %w", err)
+		}
+		log.WithField("days", len(counts)).Info("synced cases")
+	}
+
+	if s.Snapshots != nil {
+		if err := s.Snapshots.Save(ctx, stores.NewSnapshot(time.Now(), counts, from, to)); err != nil {
+			return fmt.Errorf("sync: %w", err)
+		}
+		log.Info("saved snapshot")
+	}
+
+	cases = filterCases(cases, published)
+	if s.Demographics != nil {
+		demographics := stores.FillDemographicCounts(stores.CountCasesByDemographics(cases, s.AgeBands), from, untilToday(to))
+		if err := s.Demographics.Save(ctx, demographics); err != nil {
+			return fmt.Errorf("sync: %w", err)
+		}
+		log.Info("synced demographics")
+	}
+
+	if s.ContactTracing != nil {
+		if err := s.syncContactTracing(ctx, cases, from, to); err != nil {
+			return err
+		}
+		log.Info("synced contact tracing")
+	}
+
+	if s.VaccinationStatus != nil {
+		if err := s.syncVaccinationStatus(ctx, s.VaccinationStatus, stores.ISOWeeks, from, to); err != nil {
+			return err
+		}
+		log.Info("synced vaccination status")
+	}
+	if s.EpiWeekVaccinationStatus != nil {
+		if err := s.syncVaccinationStatus(ctx, s.EpiWeekVaccinationStatus, stores.EpiWeeks, from, to); err != nil {
+			return err
+		}
+		log.Info("synced vaccination status by epi-week")
+	}
+
+	if s.Onset != nil {
+		if err := s.syncOnset(ctx, cases, from, to); err != nil {
+			return err
+		}
+		log.Info("synced onset")
+	}
+
+	if s.ReportingDelays != nil {
+		if err := s.syncReportingDelays(ctx, s.ReportingDelays, stores.ISOWeeks, from, to); err != nil {
+			return err
+		}
+		log.Info("synced reporting delays")
+	}
+	if s.EpiWeekReportingDelays != nil {
+		if err := s.syncReportingDelays(ctx, s.EpiWeekReportingDelays, stores.EpiWeeks, from, to); err != nil {
+			return err
+		}
+		log.Info("synced reporting delays by epi-week")
+	}
+
+	if s.Clusters != nil {
+		if err := s.syncClusters(ctx, s.Clusters, stores.ISOWeeks); err != nil {
+			return err
+		}
+		log.Info("synced clusters")
+	}
+	if s.EpiWeekClusters != nil {
+		if err := s.syncClusters(ctx, s.EpiWeekClusters, stores.EpiWeeks); err != nil {
+			return err
+		}
+		log.Info("synced clusters by epi-week")
+	}
+
+	if s.Deaths != nil {
+		if err := s.syncDeaths(ctx, from, to); err != nil {
+			return err
+		}
+		log.Info("synced deaths")
+	}
+
+	if s.Hospital != nil {
+		if err := s.syncHospital(ctx, from, to); err != nil {
+			return err
+		}
+		log.Info("synced hospital stats")
+	}
+
+	if s.Tests != nil {
+		if err := s.syncTests(ctx, from, to); err != nil {
+			return err
+		}
+		log.Info("synced tests")
+	}
+
+	if s.Recoveries != nil {
+		if err := s.syncRecoveries(ctx, from, to); err != nil {
+			return err
+		}
+		log.Info("synced recoveries")
+	}
+
+	if s.Alerts != nil {
+		if err := s.syncAlerts(ctx, from, to); err != nil {
+			return err
+		}
+		log.Info("synced alerts")
+	}
+
+	if s.Forecasts != nil {
+		if err := s.syncForecast(ctx); err != nil {
+			return err
+		}
+		log.Info("saved forecast")
+	}
+
+	return nil
+}
+
+// classifications returns the tracked and published classifications. The
+// published classifications are always tracked.
+func (s *Sync) classifications() (tracked, published []string) {
+	published = s.Published
+	if len(published) == 0 {
+		published = []string{stores.ClassificationConfirmed}
+	}
+	seen := map[string]bool{}
+	for _, c := range append(append([]string{}, s.Classifications...), published...) {
+		if !seen[c] {
+			seen[c] = true
+			tracked = append(tracked, c)
+		}
+	}
+	return tracked, published
+}
+
+// filterCases keeps the cases with one of the classifications
+func filterCases(cases []stores.Case, classifications []string) []stores.Case {
+	var filtered []stores.Case
+	for _, c := range cases {
+		for _, cl := range classifications {
+			if c.Classification == cl {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func (s *Sync) syncDeaths(ctx context.Context, from, to time.Time) error {
+	_, published := s.classifications()
+	deaths, err := s.Source.FindDeceasedCases(ctx, s.OutbreakID, published, from, to)
+	if err != nil {
+		return fmt.Errorf("sync deaths: %w", err)
+	}
+	deaths, err = s.Source.AddDistrictToCase(ctx, deaths)
+	if err != nil {
+		return fmt.Errorf("sync deaths: %w", err)
+	}
+	// Days left without deaths are saved too, clearing the deaths that were
+	// moved to another day or reclassified.
+	counts := stores.FillCaseCounts(stores.CountDeathsByDate(deaths), nil, from, untilToday(to))
+	if err := s.Deaths.Save(ctx, counts); err != nil {
+		return fmt.Errorf("sync deaths: %w", err)
+	}
+	return nil
+}
+
+// untilToday returns to, or the end of today when to is later
+func untilToday(to time.Time) time.Time {
+	if tomorrow := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1); to.After(tomorrow) {
+		return tomorrow
+	}
+	return to
+}
+
+func (s *Sync) syncHospital(ctx context.Context, from, to time.Time) error {
+	// Occupancy of days still to come is not known.
+	to = untilToday(to)
+	_, published := s.classifications()
+	var types []string
+	for t := range s.CareTypes {
+		types = append(types, t)
+	}
+	cases, err := s.Source.FindHospitalisedCases(ctx, s.OutbreakID, published, types, to)
+	if err != nil {
+		return fmt.Errorf("sync hospital: %w", err)
+	}
+	if err := s.Hospital.Save(ctx, stores.CountHospitalisations(cases, s.CareTypes, from, to)); err != nil {
+		return fmt.Errorf("sync hospital: %w", err)
+	}
+	return nil
+}
+
+func (s *Sync) syncRecoveries(ctx context.Context, from, to time.Time) error {
+	// Isolation periods that have not ended yet are not recoveries.
+	to = untilToday(to)
+	_, published := s.classifications()
+	recovered, err := s.Source.FindRecoveredCases(ctx, s.OutbreakID, published, from, to, s.IsolationDays)
+	if err != nil {
+		return fmt.Errorf("sync recoveries: %w", err)
+	}
+	recovered, err = s.Source.AddDistrictToCase(ctx, recovered)
+	if err != nil {
+		return fmt.Errorf("sync recoveries: %w", err)
+	}
+	undated := 0
+	for _, c := range recovered {
+		if c.OutcomeID == stores.OutcomeRecovered && c.OutcomeDate == nil {
+			undated++
+		}
+	}
+	if undated > 0 {
+		s.Logger.WithField("cases", undated).Warn("recovered cases without a date of outcome are counted on their reporting date")
+	}
+	counts := stores.FillCaseCounts(stores.CountRecoveriesByDate(recovered, s.IsolationDays), nil, from, to)
+	if err := s.Recoveries.Save(ctx, counts); err != nil {
+		return fmt.Errorf("sync recoveries: %w", err)
+	}
+	return nil
+}
+
+func (s *Sync) syncTests(ctx context.Context, from, to time.Time) error {
+	results, err := s.Source.FindLabResults(ctx, s.OutbreakID, from, to)
+	if err != nil {
+		return fmt.Errorf("sync tests: %w", err)
+	}
+	// Days left without tests are saved too. Days still to come are left
+	// alone.
+	counts := stores.FillTestCounts(stores.CountTestsByDate(results), from, untilToday(to))
+	if err := s.Tests.Save(ctx, counts); err != nil {
+		return fmt.Errorf("sync tests: %w", err)
+	}
+	return nil
+}
+
+// syncContactTracing persists the contact tracing activity. cases are the
+// published cases reported in the date range.
+func (s *Sync) syncContactTracing(ctx context.Context, cases []stores.Case, from, to time.Time) error {
+	// Follow up periods of days still to come are not known.
+	to = untilToday(to)
+	contacts, err := s.Source.FindContacts(ctx, s.OutbreakID, from, to)
+	if err != nil {
+		return fmt.Errorf("sync contact tracing: %w", err)
+	}
+	followUps, err := s.Source.FindFollowUps(ctx, s.OutbreakID, from, to)
+	if err != nil {
+		return fmt.Errorf("sync contact tracing: %w", err)
+	}
+	counts := stores.CountContactTracing(contacts, followUps, cases, from, to)
+	if err := s.ContactTracing.Save(ctx, counts); err != nil {
+		return fmt.Errorf("sync contact tracing: %w", err)
+	}
+	return nil
+}
+
+// wholeWeeks extends the date range to the weeks it overlaps
+func wholeWeeks(from, to time.Time, weeks stores.Weeks) (start, end time.Time) {
+	_, _, start = weeks.Of(from)
+	_, _, last := weeks.Of(to.AddDate(0, 0, -1))
+	return start, last.AddDate(0, 0, 7)
+}
+
+// syncVaccinationStatus persists the vaccination status of the cases in the
+// weeks overlapping the date range. Whole weeks are counted so partially
+// synced weeks are not stored, and weeks left without cases are cleared.
+func (s *Sync) syncVaccinationStatus(ctx context.Context, svc *stores.VaccinationStatusService, weeks stores.Weeks, from, to time.Time) error {
+	_, published := s.classifications()
+	start, end := wholeWeeks(from, to, weeks)
+	cases, err := s.Source.FindCases(ctx, s.OutbreakID, published, start, &end)
+	if err != nil {
+		return fmt.Errorf("sync vaccination status: %w", err)
+	}
+	deaths, err := s.Source.FindDeceasedCases(ctx, s.OutbreakID, published, start, end)
+	if err != nil {
+		return fmt.Errorf("sync vaccination status: %w", err)
+	}
+	counts := stores.FillVaccinationStatusCounts(stores.CountVaccinationStatusByWeek(cases, deaths, s.VaccinationRule, weeks), weeks, start, end)
+	if err := svc.Save(ctx, counts); err != nil {
+		return fmt.Errorf("sync vaccination status: %w", err)
+	}
+	return nil
+}
+
+// maxOnsetDays is how far before its reporting date the onset of a case is
+// recounted. Older onsets are most likely typos.
+const maxOnsetDays = 365
+
+// syncOnset persists the cases by date of onset. reported are the published
+// cases reported in the date range: the days from the earliest of their
+// onsets are recounted, so a case reported late is counted on its date of
+// onset even when that day is before the range.
+func (s *Sync) syncOnset(ctx context.Context, reported []stores.Case, from, to time.Time) error {
+	_, published := s.classifications()
+	start := from
+	for _, c := range reported {
+		if c.OnsetDate == nil || c.ReportingDate == nil {
+			continue
+		}
+		day := c.OnsetDate.UTC().Truncate(24 * time.Hour)
+		if day.Before(start) && !day.Before(c.ReportingDate.AddDate(0, 0, -maxOnsetDays)) {
+			start = day
+		}
+	}
+	end := untilToday(to)
+	cases, err := s.Source.FindCasesByOnset(ctx, s.OutbreakID, published, start, &end)
+	if err != nil {
+		return fmt.Errorf("sync onset: %w", err)
+	}
+	cases, err = s.Source.AddDistrictToCase(ctx, cases)
+	if err != nil {
+		return fmt.Errorf("sync onset: %w", err)
+	}
+	counts := stores.FillCaseCounts(stores.CountCasesByOnset(cases), nil, start, end)
+	if err := s.Onset.Save(ctx, counts); err != nil {
+		return fmt.Errorf("sync onset: %w", err)
+	}
+	return nil
+}
+
+// syncReportingDelays persists the reporting delays of the weeks overlapping
+// the date range
+func (s *Sync) syncReportingDelays(ctx context.Context, svc *stores.ReportingDelayService, weeks stores.Weeks, from, to time.Time) error {
+	_, published := s.classifications()
+	start, end := wholeWeeks(from, to, weeks)
+	cases, err := s.Source.FindCases(ctx, s.OutbreakID, published, start, &end)
+	if err != nil {
+		return fmt.Errorf("sync reporting delays: %w", err)
+	}
+	if err := svc.Save(ctx, stores.ReportingDelaysByWeek(cases, weeks)); err != nil {
+		return fmt.Errorf("sync reporting delays: %w", err)
+	}
+	return nil
+}
+
+// syncClusters recomputes the weekly cluster statistics from every case and
+// relationship of the outbreak
+func (s *Sync) syncClusters(ctx context.Context, svc *stores.ClusterService, weeks stores.Weeks) error {
+	_, published := s.classifications()
+	end := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	cases, err := s.Source.FindCases(ctx, s.OutbreakID, published, time.Time{}, &end)
+	if err != nil {
+		return fmt.Errorf("sync clusters: %w", err)
+	}
+	relationships, err := s.Source.FindRelationships(ctx, s.OutbreakID)
+	if err != nil {
+		return fmt.Errorf("sync clusters: %w", err)
+	}
+	if err := svc.Save(ctx, stores.ClusterStatsByWeek(cases, relationships, weeks)); err != nil {
+		return fmt.Errorf("sync clusters: %w", err)
+	}
+	return nil
+}
+
+// syncForecast forecasts the cases from the stored series up to yesterday, as
+// the counts of today are still coming in
+func (s *Sync) syncForecast(ctx context.Context) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	end := today.AddDate(0, 0, -1)
+	stored, err := s.Cases.FindByDateRange(ctx, end.AddDate(0, 0, -(s.Forecast.Window-1)), today)
+	if err != nil {
+		return fmt.Errorf("sync forecast: %w", err)
+	}
+	fc, err := forecast.Forecast(stores.UnitCounts(stored, stores.NationalUnit), end, s.Forecast)
+	if errors.Is(err, forecast.ErrNoCases) || errors.Is(err, growth.ErrNoFit) {
+		s.Logger.WithError(err).Warn("skipping forecast")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("sync forecast: %w", err)
+	}
+	fc.RunDate = &today
+	if err := s.Forecasts.Save(ctx, fc); err != nil {
+		return fmt.Errorf("sync forecast: %w", err)
+	}
+	return nil
+}
+
+// syncAlerts runs the aberration detection on the stored series. Today is left
+// out as its counts are still coming in.
+func (s *Sync) syncAlerts(ctx context.Context, from, to time.Time) error {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if to.After(today) {
+		to = today
+	}
+	weekFrom, weekTo := stores.WeekStart(from), stores.WeekStart(today)
+	// Fetch the baselines of the first day and of the first week.
+	start := from.AddDate(0, 0, -11)
+	if weekStart := weekFrom.AddDate(0, 0, -364*s.Alert.Years-7*s.Alert.HalfWindow); weekStart.Before(start) {
+		start = weekStart
+	}
+	stored, err := s.Cases.FindByDateRange(ctx, start, today)
+	if err != nil {
+		return fmt.Errorf("sync alerts: %w", err)
+	}
+
+	units := []string{stores.NationalUnit}
+	for _, d := range stores.Districts() {
+		units = append(units, string(d))
+	}
+	var alerts []stores.Alert
+	for _, unit := range units {
+		counts := stores.UnitCounts(stored, unit)
+		alerts = append(alerts, aberration.Daily(counts, unit, from, to, s.Alert)...)
+		alerts = append(alerts, aberration.Weekly(counts, unit, weekFrom, weekTo, s.Alert)...)
+	}
+	if err := s.Alerts.Save(ctx, alerts); err != nil {
+		return fmt.Errorf("sync alerts: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package covidstats
+
+import (
+	"covidstats/rt"
+	"covidstats/stores"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// parseRtConfig reads the serial interval and window query parameters,
+// keeping the defaults of rt.DefaultConfig for those that are missing. Its
+// errors wrap errInvalidParam, or rt.ErrInvalidConfig for a serial interval
+// that can not be built.
+func parseRtConfig(r *http.Request) (rt.Config, error) {
+	cfg := rt.DefaultConfig()
+	q := r.URL.Query()
+	if q.Get("siMean") != "" || q.Get("siSd") != "" {
+		mean, meanErr := strconv.ParseFloat(q.Get("siMean"), 64)
+		sd, sdErr := strconv.ParseFloat(q.Get("siSd"), 64)
+		if meanErr != nil || sdErr != nil {
+			return cfg, fmt.Errorf("%w: siMean and siSd must both be numbers", errInvalidParam)
+		}
+		si, err := rt.GammaSerialInterval(mean, sd)
+		if err != nil {
+			return cfg, fmt.Errorf("siMean and siSd: %w", err)
+		}
+		cfg.SerialInterval = si
+	}
+	if value := q.Get("window"); value != "" {
+		window, err := strconv.Atoi(value)
+		if err != nil || window < 1 || window > 28 {
+			return cfg, fmt.Errorf("%w: window must be between 1 and 28 days", errInvalidParam)
+		}
+		cfg.Window = window
+	}
+	return cfg, nil
+}
+
+// HandleRt is the handler that returns the estimates of the effective
+// reproduction number for the requested date range. The serial interval is a
+// gamma distribution set by the siMean and siSd query parameters, and the
+// window parameter sets the days of the sliding window.
+func (s *Server) HandleRt(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleRt")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	q := r.URL.Query()
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+	district, districtErr := parseDistrict(q.Get("district"))
+	if districtErr != nil {
+		http.Error(w, districtErr.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg, cfgErr := parseRtConfig(r)
+	if cfgErr != nil {
+		http.Error(w, cfgErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The estimate of the first day needs the cases of its window and those
+	// infecting them.
+	leadIn := len(cfg.SerialInterval) + cfg.Window
+	cases, findErr := s.casesService.FindByDateRange(r.Context(), from.AddDate(0, 0, -leadIn), to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	estimates, estErr := rt.Estimate(stores.UnitCounts(cases, district), cfg)
+	if errors.Is(estErr, rt.ErrInvalidConfig) {
+		http.Error(w, estErr.Error(), http.StatusBadRequest)
+		return
+	}
+	if estErr != nil {
+		s.logger.WithError(estErr).Error("rt.Estimate failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	result := []rt.Posterior{}
+	for _, e := range estimates {
+		if !e.Date.Before(from) {
+			result = append(result, e)
+		}
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,81 @@
+package rt
+
+import "math"
+
+const (
+	gammaEpsilon    = 1e-12
+	gammaIterations = 500
+)
+
+// gammaCDF returns the probability that a gamma distributed value is at most x
+func gammaCDF(x, shape, scale float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	return lowerGamma(shape, x/scale)
+}
+
+// gammaQuantile returns the value below which the given probability of a
+// gamma distribution lies, found by bisection of the CDF
+func gammaQuantile(p, shape, scale float64) float64 {
+	lo, hi := 0.0, shape*scale
+	for gammaCDF(hi, shape, scale) < p {
+		lo, hi = hi, hi*2
+	}
+	for i := 0; i < gammaIterations && hi-lo > gammaEpsilon*hi; i++ {
+		mid := (lo + hi) / 2
+		if gammaCDF(mid, shape, scale) < p {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// lowerGamma returns the regularised lower incomplete gamma function P(a, x),
+// using its series below a+1 and the continued fraction of its complement
+// above (Numerical Recipes, 6.2).
+func lowerGamma(a, x float64) float64 {
+	lg, _ := math.Lgamma(a)
+	prefix := math.Exp(-x + a*math.Log(x) - lg)
+
+	if x < a+1 {
+		ap, del := a, 1/a
+		sum := del
+		for i := 0; i < gammaIterations; i++ {
+			ap++
+			del *= x / ap
+			sum += del
+			if math.Abs(del) < math.Abs(sum)*gammaEpsilon {
+				break
+			}
+		}
+		return sum * prefix
+	}
+
+	const tiny = 1e-300
+	b := x + 1 - a
+	c := 1 / tiny
+	d := 1 / b
+	h := d
+	for i := 1; i <= gammaIterations; i++ {
+		an := -float64(i) * (float64(i) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		del := d * c
+		h *= del
+		if math.Abs(del-1) < gammaEpsilon {
+			break
+		}
+	}
+	return 1 - prefix*h
+}
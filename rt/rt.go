@@ -0,0 +1,203 @@
+// Package rt estimates the time-varying effective reproduction number from a
+// daily case series with the renewal equation method of Cori et al. (2013),
+// "A New Framework and Software to Estimate Time-Varying Reproduction Numbers
+// During Epidemics".
+package rt
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrInvalidConfig is returned when the estimation parameters are unusable
+var ErrInvalidConfig = errors.New("invalid rt config")
+
+// maxSerialIntervalDays bounds the length of a discretised serial interval
+const maxSerialIntervalDays = 60
+
+// minSerialIntervalMass is the share of a gamma serial interval that must
+// fall within maxSerialIntervalDays for the truncation to be negligible
+const minSerialIntervalMass = 0.99
+
+// Config holds the parameters of the estimation
+type Config struct {
+	// SerialInterval is the probability that the serial interval is s days,
+	// indexed by s. The first element must be zero. It is normalised before
+	// use.
+	SerialInterval []float64
+	// Window is the number of days of the sliding window R is assumed
+	// constant over
+	Window int
+	// PriorMean and PriorSD describe the gamma prior of R
+	PriorMean float64
+	PriorSD   float64
+}
+
+// DefaultConfig returns a config with a gamma serial interval of mean 4.7 and
+// standard deviation 2.9 days (Nishiura et al. 2020), a 7 day window and the
+// gamma prior of mean 5 and standard deviation 5 used by EpiEstim.
+func DefaultConfig() Config {
+	si, _ := GammaSerialInterval(4.7, 2.9)
+	return Config{
+		SerialInterval: si,
+		Window:         7,
+		PriorMean:      5,
+		PriorSD:        5,
+	}
+}
+
+// GammaSerialInterval discretises a gamma distributed serial interval of the
+// given mean and standard deviation in days. The probability of s days is the
+// mass between s-1 and s, with no mass on day zero. The distribution must
+// fall almost entirely within the first 60 days.
+func GammaSerialInterval(mean, sd float64) ([]float64, error) {
+	if mean <= 0 || sd <= 0 {
+		return nil, fmt.Errorf("%w: serial interval mean and sd must be positive", ErrInvalidConfig)
+	}
+	shape := mean * mean / (sd * sd)
+	scale := sd * sd / mean
+
+	si := []float64{0}
+	prev := gammaCDF(0, shape, scale)
+	for s := 1; s <= maxSerialIntervalDays; s++ {
+		cdf := gammaCDF(float64(s), shape, scale)
+		si = append(si, cdf-prev)
+		prev = cdf
+		if cdf >= 0.999 {
+			break
+		}
+	}
+	if !(prev-gammaCDF(0, shape, scale) >= minSerialIntervalMass) {
+		return nil, fmt.Errorf("%w: serial interval must fall within %d days", ErrInvalidConfig, maxSerialIntervalDays)
+	}
+	if err := validSerialInterval(si); err != nil {
+		return nil, err
+	}
+	return normalise(si), nil
+}
+
+// Posterior is the posterior of R over the window ending on Date
+type Posterior struct {
+	Date time.Time `json:"date"`
+	// Cases is the number of cases in the window
+	Cases  int     `json:"cases"`
+	Mean   float64 `json:"mean"`
+	SD     float64 `json:"sd"`
+	Median float64 `json:"median"`
+	// Lower and Upper bound the 95% credible interval
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Estimate returns the estimates of R for every window of the case series
+// that is preceded by at least one case. Days missing from the series count
+// as zero cases.
+func Estimate(counts []stores.CaseCount, cfg Config) ([]Posterior, error) {
+	if cfg.Window < 1 {
+		return nil, fmt.Errorf("%w: window must be at least one day", ErrInvalidConfig)
+	}
+	if cfg.PriorMean <= 0 || cfg.PriorSD <= 0 {
+		return nil, fmt.Errorf("%w: prior mean and sd must be positive", ErrInvalidConfig)
+	}
+	if err := validSerialInterval(cfg.SerialInterval); err != nil {
+		return nil, err
+	}
+	si := normalise(cfg.SerialInterval)
+
+	daily := series.Daily{}
+	var first, last time.Time
+	for _, c := range counts {
+		if c.ReportingDate == nil {
+			continue
+		}
+		day := c.ReportingDate.UTC().Truncate(24 * time.Hour)
+		daily.Add(day, c.Count)
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+		if day.After(last) {
+			last = day
+		}
+	}
+	if first.IsZero() {
+		return []Posterior{}, nil
+	}
+
+	var days []time.Time
+	var incidence []float64
+	for day := first; !day.After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+		incidence = append(incidence, float64(daily.Get(day)))
+	}
+
+	// Lambda is the infectiousness of the previous cases on each day.
+	lambda := make([]float64, len(incidence))
+	for t := range incidence {
+		for s := 1; s < len(si) && s <= t; s++ {
+			lambda[t] += incidence[t-s] * si[s]
+		}
+	}
+
+	priorShape := cfg.PriorMean * cfg.PriorMean / (cfg.PriorSD * cfg.PriorSD)
+	priorScale := cfg.PriorSD * cfg.PriorSD / cfg.PriorMean
+
+	estimates := []Posterior{}
+	for t := cfg.Window; t < len(incidence); t++ {
+		var cases, infectiousness float64
+		for i := t - cfg.Window + 1; i <= t; i++ {
+			cases += incidence[i]
+			infectiousness += lambda[i]
+		}
+		if infectiousness == 0 {
+			continue
+		}
+		shape := priorShape + cases
+		scale := 1 / (1/priorScale + infectiousness)
+		estimates = append(estimates, Posterior{
+			Date:   days[t],
+			Cases:  int(cases),
+			Mean:   shape * scale,
+			SD:     math.Sqrt(shape) * scale,
+			Median: gammaQuantile(0.5, shape, scale),
+			Lower:  gammaQuantile(0.025, shape, scale),
+			Upper:  gammaQuantile(0.975, shape, scale),
+		})
+	}
+	return estimates, nil
+}
+
+// validSerialInterval checks the serial interval is a distribution with no
+// mass on day zero
+func validSerialInterval(si []float64) error {
+	if len(si) < 2 || si[0] != 0 {
+		return fmt.Errorf("%w: serial interval must start with a zero for day zero", ErrInvalidConfig)
+	}
+	total := 0.0
+	for _, p := range si {
+		if p < 0 || math.IsNaN(p) || math.IsInf(p, 0) {
+			return fmt.Errorf("%w: serial interval probabilities must be finite and not negative", ErrInvalidConfig)
+		}
+		total += p
+	}
+	if total == 0 {
+		return fmt.Errorf("%w: serial interval has no mass", ErrInvalidConfig)
+	}
+	return nil
+}
+
+// normalise returns a copy of the distribution scaled to sum to one
+func normalise(p []float64) []float64 {
+	total := 0.0
+	for _, v := range p {
+		total += v
+	}
+	out := make([]float64, len(p))
+	for i, v := range p {
+		out[i] = v / total
+	}
+	return out
+}
@@ -0,0 +1,113 @@
+package rt
+
+import (
+	"covidstats/stores/storestest"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestGammaSerialInterval(t *testing.T) {
+	si, err := GammaSerialInterval(4.7, 2.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if si[0] != 0 {
+		t.Errorf("expected no mass on day zero, got %f", si[0])
+	}
+	total, mean := 0.0, 0.0
+	for s, p := range si {
+		total += p
+		mean += float64(s) * p
+	}
+	if math.Abs(total-1) > 1e-9 {
+		t.Errorf("expected serial interval to sum to 1, got %f", total)
+	}
+	// Assigning the mass of (s-1, s] to s shifts the mean up by about half a day.
+	if math.Abs(mean-5.2) > 0.1 {
+		t.Errorf("expected a mean close to 5.2 days, got %f", mean)
+	}
+	if _, err := GammaSerialInterval(0, 1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+	// Almost all of the mass lies far beyond the 60 days that are kept.
+	if _, err := GammaSerialInterval(1000, 1); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig, got %v", err)
+	}
+}
+
+func TestGammaQuantile(t *testing.T) {
+	// The exponential distribution has its median at ln 2.
+	if q := gammaQuantile(0.5, 1, 1); math.Abs(q-math.Ln2) > 1e-6 {
+		t.Errorf("expected median %f, got %f", math.Ln2, q)
+	}
+	// The chi-squared distribution with 10 degrees of freedom has its 97.5%
+	// quantile at 20.483.
+	if q := gammaQuantile(0.975, 5, 2); math.Abs(q-20.483) > 1e-3 {
+		t.Errorf("expected quantile 20.483, got %f", q)
+	}
+}
+
+func TestEstimate_Constant(t *testing.T) {
+	values := make([]int, 40)
+	for i := range values {
+		values[i] = 100
+	}
+	estimates, err := Estimate(storestest.Counts("2020-12-10", values...), DefaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := estimates[len(estimates)-1]
+	if last.Date.Format("2006-01-02") != "2021-01-18" {
+		t.Errorf("expected the last estimate on 2021-01-18, got %s", last.Date)
+	}
+	if math.Abs(last.Mean-1) > 0.01 {
+		t.Errorf("expected R close to 1 with constant incidence, got %f", last.Mean)
+	}
+	if !(last.Lower < last.Median && last.Median < last.Upper) {
+		t.Errorf("expected lower < median < upper, got %+v", last)
+	}
+	if last.Cases != 700 {
+		t.Errorf("expected 700 cases in the window, got %d", last.Cases)
+	}
+}
+
+func TestEstimate_Doubling(t *testing.T) {
+	cfg := Config{SerialInterval: []float64{0, 1}, Window: 3, PriorMean: 5, PriorSD: 5}
+	estimates, err := Estimate(storestest.Counts("2021-03-01", 10, 20, 40, 80, 160, 320), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(estimates) != 3 {
+		t.Fatalf("expected 3 estimates, got %d", len(estimates))
+	}
+	for _, e := range estimates {
+		if math.Abs(e.Mean-2) > 0.02 {
+			t.Errorf("expected R close to 2 on %s, got %f", e.Date, e.Mean)
+		}
+	}
+}
+
+func TestEstimate_SkipsWindowsWithoutPriorCases(t *testing.T) {
+	cfg := Config{SerialInterval: []float64{0, 1}, Window: 1, PriorMean: 5, PriorSD: 5}
+	estimates, err := Estimate(storestest.Counts("2021-03-01", 0, 0, 5, 5), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(estimates) != 1 || estimates[0].Date.Format("2006-01-02") != "2021-03-04" {
+		t.Errorf("expected a single estimate on 2021-03-04, got %+v", estimates)
+	}
+}
+
+func TestEstimate_InvalidConfig(t *testing.T) {
+	for _, cfg := range []Config{
+		{SerialInterval: []float64{0, 1}, Window: 0, PriorMean: 5, PriorSD: 5},
+		{SerialInterval: []float64{0.5, 0.5}, Window: 7, PriorMean: 5, PriorSD: 5},
+		{SerialInterval: []float64{0, -1, 2}, Window: 7, PriorMean: 5, PriorSD: 5},
+		{SerialInterval: []float64{0, 1}, Window: 7, PriorMean: 0, PriorSD: 5},
+	} {
+		if _, err := Estimate(nil, cfg); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for %+v, got %v", cfg, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package series
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidGranularity is returned for an unknown bucket granularity
+var ErrInvalidGranularity = errors.New("invalid granularity")
+
+// Bucket granularities
+const (
+	GranularityDay     = "day"
+	GranularityWeek    = "week"
+	GranularityEpiWeek = "epiweek"
+	GranularityMonth   = "month"
+	GranularityYear    = "year"
+)
+
+// Bucket is a span of days counts are summed over. Key identifies it in the
+// format the stats store uses for the granularity.
+type Bucket struct {
+	Key  string
+	From time.Time
+	To   time.Time
+}
+
+// BucketOf returns the bucket of the granularity holding the day. Weeks are
+// ISO weeks, epiweeks MMWR epidemiological weeks.
+func BucketOf(day time.Time, granularity string) (Bucket, error) {
+	day = day.UTC().Truncate(24 * time.Hour)
+	switch granularity {
+	case GranularityDay:
+		return Bucket{Key: day.Format(Layout), From: day, To: day}, nil
+	case GranularityWeek:
+		year, week := day.ISOWeek()
+		monday := WeekStart(day)
+		return Bucket{Key: fmt.Sprintf("%d-%d", year, week), From: monday, To: monday.AddDate(0, 0, 6)}, nil
+	case GranularityEpiWeek:
+		sunday := EpiWeekStart(day)
+		return Bucket{Key: EpiWeekKey(day), From: sunday, To: sunday.AddDate(0, 0, 6)}, nil
+	case GranularityMonth:
+		first := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return Bucket{Key: first.Format("2006-01"), From: first, To: first.AddDate(0, 1, -1)}, nil
+	case GranularityYear:
+		first := time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return Bucket{Key: first.Format("2006"), From: first, To: first.AddDate(1, 0, -1)}, nil
+	default:
+		return Bucket{}, fmt.Errorf("%w: %s", ErrInvalidGranularity, granularity)
+	}
+}
@@ -0,0 +1,37 @@
+package series
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBucketOf(t *testing.T) {
+	tests := []struct {
+		day, granularity string
+		key, from, to    string
+	}{
+		{"2021-01-02", GranularityDay, "2021-01-02", "2021-01-02", "2021-01-02"},
+		{"2021-01-02", GranularityWeek, "2020-53", "2020-12-28", "2021-01-03"},
+		{"2021-01-03", GranularityWeek, "2020-53", "2020-12-28", "2021-01-03"},
+		{"2021-01-02", GranularityEpiWeek, "2020-53", "2020-12-27", "2021-01-02"},
+		{"2021-01-03", GranularityEpiWeek, "2021-1", "2021-01-03", "2021-01-09"},
+		{"2020-02-15", GranularityMonth, "2020-02", "2020-02-01", "2020-02-29"},
+		{"2020-02-15", GranularityYear, "2020", "2020-01-01", "2020-12-31"},
+	}
+	for _, tt := range tests {
+		day, _ := time.Parse(Layout, tt.day)
+		b, err := BucketOf(day, tt.granularity)
+		if err != nil {
+			t.Errorf("BucketOf(%s, %s) failed: %v", tt.day, tt.granularity, err)
+			continue
+		}
+		if b.Key != tt.key || b.From.Format(Layout) != tt.from || b.To.Format(Layout) != tt.to {
+			t.Errorf("BucketOf(%s, %s) = %s %s..%s, want %s %s..%s", tt.day, tt.granularity,
+				b.Key, b.From.Format(Layout), b.To.Format(Layout), tt.key, tt.from, tt.to)
+		}
+	}
+	if _, err := BucketOf(time.Now(), "quarter"); !errors.Is(err, ErrInvalidGranularity) {
+		t.Errorf("expected ErrInvalidGranularity, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package series
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// epiWeekPattern matches an epidemiological week as yyyy-w or yyyy-ww
+var epiWeekPattern = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+
+// EpiWeekStart returns the Sunday starting the MMWR epidemiological week of
+// the date
+func EpiWeekStart(date time.Time) time.Time {
+	day := date.UTC().Truncate(24 * time.Hour)
+	return day.AddDate(0, 0, -int(day.Weekday()))
+}
+
+// EpiWeek returns the year and number of the MMWR epidemiological week of the
+// date, as used by PAHO. Weeks run from Sunday to Saturday and the first week
+// of a year is the first one with at least four of its days in that year, so
+// a year has 52 or 53 weeks and the days around the new year can belong to
+// the previous or the next epidemiological year.
+func EpiWeek(date time.Time) (year, week int) {
+	// A week has four days in the year of its Wednesday.
+	wednesday := EpiWeekStart(date).AddDate(0, 0, 3)
+	return wednesday.Year(), (wednesday.YearDay()-1)/7 + 1
+}
+
+// EpiWeekKey returns the epidemiological week of the date as yyyy-w, the
+// format ISO weeks are stored with
+func EpiWeekKey(date time.Time) string {
+	year, week := EpiWeek(date)
+	return fmt.Sprintf("%d-%d", year, week)
+}
+
+// ParseEpiWeek parses an epidemiological week written as yyyy-w or yyyy-ww,
+// returning the Sunday it starts on. Weeks the year does not have, such as
+// week 53 of most years, are rejected.
+func ParseEpiWeek(s string) (time.Time, error) {
+	m := epiWeekPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidPeriod, s)
+	}
+	year, _ := strconv.Atoi(m[1])
+	week, _ := strconv.Atoi(m[2])
+	// The first week holds the 4th of January.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	sunday := EpiWeekStart(jan4).AddDate(0, 0, 7*(week-1))
+	if y, w := EpiWeek(sunday); y != year || w != week {
+		return time.Time{}, fmt.Errorf("%w: %d has no epidemiological week %d", ErrInvalidPeriod, year, week)
+	}
+	return sunday, nil
+}
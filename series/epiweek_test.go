@@ -0,0 +1,65 @@
+package series
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEpiWeek(t *testing.T) {
+	tests := []struct {
+		date  string
+		year  int
+		week  int
+		start string
+	}{
+		{"2020-01-01", 2020, 1, "2019-12-29"},
+		{"2020-12-31", 2020, 53, "2020-12-27"},
+		{"2021-01-02", 2020, 53, "2020-12-27"},
+		{"2021-01-03", 2021, 1, "2021-01-03"},
+		{"2022-01-01", 2021, 52, "2021-12-26"},
+		{"2022-01-02", 2022, 1, "2022-01-02"},
+		{"2014-12-31", 2014, 53, "2014-12-28"},
+		{"2015-01-03", 2014, 53, "2014-12-28"},
+		{"2019-12-31", 2020, 1, "2019-12-29"},
+		{"2021-08-14", 2021, 32, "2021-08-08"},
+	}
+	for _, tt := range tests {
+		d, _ := time.Parse(Layout, tt.date)
+		year, week := EpiWeek(d)
+		if year != tt.year || week != tt.week {
+			t.Errorf("EpiWeek(%s) = %d-%d, want %d-%d", tt.date, year, week, tt.year, tt.week)
+		}
+		if got := EpiWeekStart(d).Format(Layout); got != tt.start {
+			t.Errorf("EpiWeekStart(%s) = %s, want %s", tt.date, got, tt.start)
+		}
+	}
+}
+
+func TestParseEpiWeek(t *testing.T) {
+	tests := []struct {
+		week  string
+		start string
+	}{
+		{"2021-1", "2021-01-03"},
+		{"2021-01", "2021-01-03"},
+		{"2020-53", "2020-12-27"},
+		{"2021-52", "2021-12-26"},
+		{"2020-1", "2019-12-29"},
+	}
+	for _, tt := range tests {
+		start, err := ParseEpiWeek(tt.week)
+		if err != nil {
+			t.Errorf("ParseEpiWeek(%s) failed: %v", tt.week, err)
+			continue
+		}
+		if got := start.Format(Layout); got != tt.start {
+			t.Errorf("ParseEpiWeek(%s) = %s, want %s", tt.week, got, tt.start)
+		}
+	}
+	for _, week := range []string{"2021-53", "2021-0", "2021-W3", "21-3"} {
+		if _, err := ParseEpiWeek(week); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("ParseEpiWeek(%s): expected ErrInvalidPeriod, got %v", week, err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package series
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPeriod is returned when a period cannot be parsed
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// Period kinds
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodRange = "range"
+)
+
+var (
+	weekPattern    = regexp.MustCompile(`^(\d{4})-W(\d{1,2})$`)
+	monthPattern   = regexp.MustCompile(`^(\d{4})-(\d{2})$`)
+	isoWeekPattern = regexp.MustCompile(`^(\d{4})-(\d{1,2})$`)
+)
+
+// Period is a span of whole days from From to To, both included
+type Period struct {
+	Kind string
+	From time.Time
+	To   time.Time
+}
+
+// WeekStart returns the Monday starting the ISO week of the date
+func WeekStart(date time.Time) time.Time {
+	day := date.UTC().Truncate(24 * time.Hour)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// ParseISOWeek parses an ISO week written as yyyy-w or yyyy-ww, the way the
+// weekly statistics are keyed, returning the Monday it starts on. Weeks the
+// year does not have, such as week 53 of most years, are rejected.
+func ParseISOWeek(s string) (time.Time, error) {
+	m := isoWeekPattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("%w: %s", ErrInvalidPeriod, s)
+	}
+	return isoWeekStart(m[1], m[2])
+}
+
+// isoWeekStart returns the Monday starting the week of the year
+func isoWeekStart(yearDigits, weekDigits string) (time.Time, error) {
+	year, _ := strconv.Atoi(yearDigits)
+	week, _ := strconv.Atoi(weekDigits)
+	// The first ISO week is the one holding the 4th of January.
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.UTC)
+	monday := WeekStart(jan4).AddDate(0, 0, 7*(week-1))
+	if y, w := monday.ISOWeek(); y != year || w != week {
+		return time.Time{}, fmt.Errorf("%w: %d has no week %d", ErrInvalidPeriod, year, week)
+	}
+	return monday, nil
+}
+
+// ParsePeriod parses an ISO week (yyyy-Www), a month (yyyy-mm) or a range of
+// days (yyyy-mm-dd/yyyy-mm-dd)
+func ParsePeriod(s string) (Period, error) {
+	if m := weekPattern.FindStringSubmatch(s); m != nil {
+		monday, err := isoWeekStart(m[1], m[2])
+		if err != nil {
+			return Period{}, err
+		}
+		return Period{Kind: PeriodWeek, From: monday, To: monday.AddDate(0, 0, 6)}, nil
+	}
+	if monthPattern.MatchString(s) {
+		first, err := time.Parse("2006-01", s)
+		if err != nil {
+			return Period{}, fmt.Errorf("%w: %s", ErrInvalidPeriod, s)
+		}
+		return Period{Kind: PeriodMonth, From: first, To: first.AddDate(0, 1, -1)}, nil
+	}
+	if parts := strings.Split(s, "/"); len(parts) == 2 {
+		from, fromErr := time.Parse(Layout, parts[0])
+		to, toErr := time.Parse(Layout, parts[1])
+		if fromErr != nil || toErr != nil || to.Before(from) {
+			return Period{}, fmt.Errorf("%w: %s", ErrInvalidPeriod, s)
+		}
+		return Period{Kind: PeriodRange, From: from, To: to}, nil
+	}
+	return Period{}, fmt.Errorf("%w: %s", ErrInvalidPeriod, s)
+}
+
+// String formats the period the way ParsePeriod reads it
+func (p Period) String() string {
+	switch p.Kind {
+	case PeriodWeek:
+		year, week := p.From.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodMonth:
+		return p.From.Format("2006-01")
+	default:
+		return p.From.Format(Layout) + "/" + p.To.Format(Layout)
+	}
+}
+
+// Days returns the number of days in the period
+func (p Period) Days() int {
+	return int(p.To.Sub(p.From).Hours()/24) + 1
+}
+
+// Previous returns the period of the same kind just before p. The previous
+// range has as many days as p.
+func (p Period) Previous() Period {
+	switch p.Kind {
+	case PeriodWeek:
+		return Period{Kind: PeriodWeek, From: p.From.AddDate(0, 0, -7), To: p.From.AddDate(0, 0, -1)}
+	case PeriodMonth:
+		return Period{Kind: PeriodMonth, From: p.From.AddDate(0, -1, 0), To: p.From.AddDate(0, 0, -1)}
+	default:
+		return Period{Kind: PeriodRange, From: p.From.AddDate(0, 0, -p.Days()), To: p.From.AddDate(0, 0, -1)}
+	}
+}
+
+// Sum returns the total over the period
+func (p Period) Sum(d Daily) int {
+	return d.Sum(p.To, p.Days())
+}
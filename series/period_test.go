@@ -0,0 +1,82 @@
+package series
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParsePeriod(t *testing.T) {
+	tests := []struct {
+		in       string
+		from, to string
+		days     int
+		previous string
+	}{
+		{"2021-W01", "2021-01-04", "2021-01-10", 7, "2020-W53"},
+		{"2020-W53", "2020-12-28", "2021-01-03", 7, "2020-W52"},
+		{"2019-W01", "2018-12-31", "2019-01-06", 7, "2018-W52"},
+		{"2021-03", "2021-03-01", "2021-03-31", 31, "2021-02"},
+		{"2021-01", "2021-01-01", "2021-01-31", 31, "2020-12"},
+		{"2020-12-28/2021-01-03", "2020-12-28", "2021-01-03", 7, "2020-12-21/2020-12-27"},
+	}
+	for _, tt := range tests {
+		p, err := ParsePeriod(tt.in)
+		if err != nil {
+			t.Errorf("ParsePeriod(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if p.From.Format(Layout) != tt.from || p.To.Format(Layout) != tt.to || p.Days() != tt.days {
+			t.Errorf("ParsePeriod(%q) = %s..%s (%d days), want %s..%s (%d days)",
+				tt.in, p.From.Format(Layout), p.To.Format(Layout), p.Days(), tt.from, tt.to, tt.days)
+		}
+		if p.String() != tt.in {
+			t.Errorf("String() = %q, want %q", p.String(), tt.in)
+		}
+		if got := p.Previous().String(); got != tt.previous {
+			t.Errorf("Previous() of %q = %q, want %q", tt.in, got, tt.previous)
+		}
+	}
+}
+
+func TestParsePeriod_Invalid(t *testing.T) {
+	for _, in := range []string{"", "2021-W53", "2021-W00", "2021-13", "2021-32", "2021-02-10/2021-02-01", "2021"} {
+		if _, err := ParsePeriod(in); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("ParsePeriod(%q) error = %v, want ErrInvalidPeriod", in, err)
+		}
+	}
+}
+
+func TestPeriod_Sum(t *testing.T) {
+	d := Daily{"2021-02-28": 2, "2021-03-01": 3, "2021-03-31": 4, "2021-04-01": 5}
+	p, _ := ParsePeriod("2021-03")
+	if got := p.Sum(d); got != 7 {
+		t.Errorf("Sum() = %d, want 7", got)
+	}
+}
+
+func TestParseISOWeek(t *testing.T) {
+	tests := []struct {
+		week  string
+		start string
+	}{
+		{"2021-5", "2021-02-01"},
+		{"2021-05", "2021-02-01"},
+		{"2020-53", "2020-12-28"},
+		{"2019-1", "2018-12-31"},
+	}
+	for _, tt := range tests {
+		start, err := ParseISOWeek(tt.week)
+		if err != nil {
+			t.Errorf("ParseISOWeek(%s) failed: %v", tt.week, err)
+			continue
+		}
+		if got := start.Format(Layout); got != tt.start {
+			t.Errorf("ParseISOWeek(%s) = %s, want %s", tt.week, got, tt.start)
+		}
+	}
+	for _, week := range []string{"2021-53", "2021-0", "2021-W5", "21-5"} {
+		if _, err := ParseISOWeek(week); !errors.Is(err, ErrInvalidPeriod) {
+			t.Errorf("ParseISOWeek(%s): expected ErrInvalidPeriod, got %v", week, err)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Package series provides helpers for working with daily case series.
+package series
+
+import (
+	"time"
+)
+
+// Layout is the date layout used to key daily values
+const Layout = "2006-01-02"
+
+// Daily holds values keyed by calendar day. Days without a value count as zero.
+type Daily map[string]int
+
+// Add adds n to the value of the day
+func (d Daily) Add(day time.Time, n int) {
+	d[day.Format(Layout)] += n
+}
+
+// Get returns the value of the day
+func (d Daily) Get(day time.Time) int {
+	return d[day.Format(Layout)]
+}
+
+// Sum returns the total over the given number of days ending on, and
+// including, end.
+func (d Daily) Sum(end time.Time, days int) int {
+	total := 0
+	for i := 0; i < days; i++ {
+		total += d.Get(end.AddDate(0, 0, -i))
+	}
+	return total
+}
+
+// Window is how a rolling window is aligned on a day
+type Window int
+
+const (
+	// Trailing windows end on the day
+	Trailing Window = iota
+	// Centered windows have as many days before the day as after it
+	Centered
+)
+
+// Span returns the first and last day of the window of the given number of
+// days for day. Centered windows of an even number of days have one day
+// more before the day than after it.
+func (w Window) Span(day time.Time, days int) (first, last time.Time) {
+	if w == Centered {
+		after := (days - 1) / 2
+		last = day.AddDate(0, 0, after)
+		return last.AddDate(0, 0, -(days - 1)), last
+	}
+	return day.AddDate(0, 0, -(days - 1)), day
+}
+
+// Mean returns the average over the window of the given number of days
+func (d Daily) Mean(day time.Time, days int, w Window) float64 {
+	_, last := w.Span(day, days)
+	return float64(d.Sum(last, days)) / float64(days)
+}
+
+// Point is the value of a day along with the running total of the series up
+// to and including the day
+type Point struct {
+	Date       time.Time `json:"date"`
+	Value      int       `json:"value"`
+	Cumulative int       `json:"cumulative"`
+}
+
+// Points returns a point for every day from (inclusive) to to (inclusive),
+// with days without a value as zero. The running totals include every day
+// of the series before from, so they count from its first day.
+func (d Daily) Points(from, to time.Time) []Point {
+	total := 0
+	start := from.Format(Layout)
+	for k, v := range d {
+		// Days use a sortable layout, so they compare as strings.
+		if k < start {
+			total += v
+		}
+	}
+	points := []Point{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		v := d.Get(day)
+		total += v
+		points = append(points, Point{Date: day, Value: v, Cumulative: total})
+	}
+	return points
+}
+
+// First returns the earliest day with a value
+func (d Daily) First() (time.Time, bool) {
+	var first time.Time
+	for k := range d {
+		day, err := time.Parse(Layout, k)
+		if err != nil {
+			continue
+		}
+		if first.IsZero() || day.Before(first) {
+			first = day
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// FirstNonZero returns the earliest day with a value other than zero, such as
+// the first case of a zero filled series
+func (d Daily) FirstNonZero() (time.Time, bool) {
+	nonZero := Daily{}
+	for k, v := range d {
+		if v != 0 {
+			nonZero[k] = v
+		}
+	}
+	return nonZero.First()
+}
+
+// Per100k returns the count per 100,000 population
+func Per100k(count, population int) float64 {
+	if population <= 0 {
+		return 0
+	}
+	return float64(count) * 100000 / float64(population)
+}
+
+// Quantile returns the q-th quantile of the sorted values, interpolating
+// between the closest ranks. It returns 0 when there are no values.
+func Quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lo := int(pos)
+	if lo >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lo)
+	return sorted[lo] + frac*(sorted[lo+1]-sorted[lo])
+}
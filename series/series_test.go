@@ -0,0 +1,122 @@
+package series
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDaily_Sum(t *testing.T) {
+	d := Daily{}
+	start, _ := time.Parse(Layout, "2020-12-28")
+	for i := 0; i < 10; i++ {
+		d.Add(start.AddDate(0, 0, i), i+1)
+	}
+
+	end, _ := time.Parse(Layout, "2021-01-03")
+	if got := d.Sum(end, 7); got != 28 {
+		t.Errorf("7 day sum across the year boundary = %d, want 28", got)
+	}
+	if got := d.Sum(end, 14); got != 28 {
+		t.Errorf("14 day sum with missing days = %d, want 28", got)
+	}
+}
+
+func TestPer100k(t *testing.T) {
+	if got := Per100k(25, 50000); got != 50 {
+		t.Errorf("Per100k(25, 50000) = %v, want 50", got)
+	}
+	if got := Per100k(25, 0); got != 0 {
+		t.Errorf("Per100k with no population = %v, want 0", got)
+	}
+}
+
+func TestDaily_First(t *testing.T) {
+	if _, ok := (Daily{}).First(); ok {
+		t.Errorf("empty series should have no first day")
+	}
+	d := Daily{"2021-03-02": 1, "2020-12-31": 0, "2021-01-01": 4}
+	first, ok := d.First()
+	if !ok || first.Format(Layout) != "2020-12-31" {
+		t.Errorf("First() = %v, %v, want 2020-12-31", first, ok)
+	}
+}
+
+func TestDaily_FirstNonZero(t *testing.T) {
+	d := Daily{"2021-03-02": 1, "2020-12-31": 0, "2021-01-01": 4}
+	first, ok := d.FirstNonZero()
+	if !ok || first.Format(Layout) != "2021-01-01" {
+		t.Errorf("FirstNonZero() = %v, %v, want 2021-01-01", first, ok)
+	}
+	if _, ok := (Daily{"2021-01-01": 0}).FirstNonZero(); ok {
+		t.Errorf("a series of zeros should have no first non zero day")
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		q    float64
+		want float64
+	}{
+		{0, 1},
+		{0.25, 3.25},
+		{0.5, 5.5},
+		{0.75, 7.75},
+		{1, 10},
+	}
+	for _, tt := range tests {
+		if got := Quantile(values, tt.q); got != tt.want {
+			t.Errorf("Quantile(%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if got := Quantile(nil, 0.5); got != 0 {
+		t.Errorf("Quantile of no values = %v, want 0", got)
+	}
+}
+
+func TestDaily_Mean(t *testing.T) {
+	d := Daily{}
+	start, _ := time.Parse(Layout, "2020-12-25")
+	for i := 0; i < 14; i++ {
+		d.Add(start.AddDate(0, 0, i), i)
+	}
+
+	day, _ := time.Parse(Layout, "2021-01-01")
+	if got := d.Mean(day, 7, Trailing); got != 4 {
+		t.Errorf("trailing mean = %v, want 4", got)
+	}
+	if got := d.Mean(day, 7, Centered); got != 7 {
+		t.Errorf("centered mean = %v, want 7", got)
+	}
+
+	first, last := Centered.Span(day, 7)
+	if first.Format(Layout) != "2020-12-29" || last.Format(Layout) != "2021-01-04" {
+		t.Errorf("centered span = %s..%s, want 2020-12-29..2021-01-04", first.Format(Layout), last.Format(Layout))
+	}
+}
+
+func TestDaily_Points(t *testing.T) {
+	d := Daily{"2020-03-23": 2, "2020-12-30": 3, "2021-01-02": 4, "2021-01-05": 1}
+	from, _ := time.Parse(Layout, "2020-12-31")
+	to, _ := time.Parse(Layout, "2021-01-03")
+
+	points := d.Points(from, to)
+	want := []struct {
+		date       string
+		value, cum int
+	}{
+		{"2020-12-31", 0, 5},
+		{"2021-01-01", 0, 5},
+		{"2021-01-02", 4, 9},
+		{"2021-01-03", 0, 9},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i, w := range want {
+		p := points[i]
+		if p.Date.Format(Layout) != w.date || p.Value != w.value || p.Cumulative != w.cum {
+			t.Errorf("point %d = %s %d %d, want %s %d %d", i, p.Date.Format(Layout), p.Value, p.Cumulative, w.date, w.value, w.cum)
+		}
+	}
+}
@@ -17,27 +17,85 @@ import (
 
 // Server encapsulates the covidstats backend
 type Server struct {
-	GCPProjectID    string
-	FirestoreClient *stores.Firestore
-	casesService    *stores.CasesByDateService
-	router          *mux.Router
-	logger          *logrus.Logger
+	GCPProjectID      string
+	FirestoreClient   *stores.Firestore
+	casesService      *stores.CasesByDateService
+	deathsService     *stores.CasesByDateService
+	recoveriesService *stores.CasesByDateService
+	demographics      *stores.DemographicsService
+	hospital          *stores.HospitalService
+	tests             *stores.TestsService
+	contactTracing    *stores.ContactTracingService
+	vaccinationStatus *stores.VaccinationStatusService
+	onsetService      *stores.CasesByDateService
+	reportingDelays   *stores.ReportingDelayService
+	clusters          *stores.ClusterService
+	snapshots         *stores.SnapshotService
+	forecasts         *stores.ForecastService
+	alerts            *stores.AlertService
+	router            *mux.Router
+	logger            *logrus.Logger
+	population        *stores.Population
+	boundaries        *stores.Boundaries
+
+	// The weekly statistics by MMWR epidemiological week
+	epiWeekVaccinationStatus *stores.VaccinationStatusService
+	epiWeekReportingDelays   *stores.ReportingDelayService
+	epiWeekClusters          *stores.ClusterService
+}
+
+// Option configures optional features of the server
+type Option func(*Server)
+
+// WithPopulation sets the population denominators used for incidence rates
+func WithPopulation(p *stores.Population) Option {
+	return func(s *Server) {
+		s.population = p
+	}
+}
+
+// WithBoundaries sets the district boundaries used for GeoJSON output
+func WithBoundaries(b *stores.Boundaries) Option {
+	return func(s *Server) {
+		s.boundaries = b
+	}
 }
 
 // NewServer instantiates new server
-func NewServer(ctx context.Context, logger *logrus.Logger, gcpProjectID string) (*Server, error) {
+func NewServer(ctx context.Context, logger *logrus.Logger, gcpProjectID string, opts ...Option) (*Server, error) {
 	firestoreClient, err := stores.CreateFirestoreDB(ctx, gcpProjectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate firestore client: %w", err)
 	}
 	svc := stores.NewCasesByDateService(firestoreClient, "covid_cases_stats")
-	return &Server{
-		GCPProjectID:    gcpProjectID,
-		FirestoreClient: firestoreClient,
-		casesService:    svc,
-		router:          mux.NewRouter().PathPrefix("/api").Subrouter(),
-		logger:          logger,
-	}, nil
+	s := &Server{
+		GCPProjectID:      gcpProjectID,
+		FirestoreClient:   firestoreClient,
+		casesService:      svc,
+		deathsService:     stores.NewCasesByDateService(firestoreClient, "covid_deaths_stats"),
+		recoveriesService: stores.NewCasesByDateService(firestoreClient, "covid_recoveries_stats"),
+		demographics:      stores.NewDemographicsService(firestoreClient, "covid_cases_demographics"),
+		hospital:          stores.NewHospitalService(firestoreClient, "covid_hospital_stats"),
+		tests:             stores.NewTestsService(firestoreClient, "covid_tests_stats"),
+		contactTracing:    stores.NewContactTracingService(firestoreClient, "covid_contact_tracing_stats"),
+		vaccinationStatus: stores.NewVaccinationStatusService(firestoreClient, "covid_vaccination_status_stats"),
+		onsetService:      stores.NewCasesByDateService(firestoreClient, "covid_onset_stats"),
+		reportingDelays:   stores.NewReportingDelayService(firestoreClient, "covid_reporting_delay_stats"),
+		clusters:          stores.NewClusterService(firestoreClient, "covid_cluster_stats"),
+		snapshots:         stores.NewSnapshotService(firestoreClient, "covid_cases_snapshots"),
+		forecasts:         stores.NewForecastService(firestoreClient, "covid_cases_forecasts"),
+		alerts:            stores.NewAlertService(firestoreClient, "covid_alerts"),
+		router:            mux.NewRouter().PathPrefix("/api").Subrouter(),
+		logger:            logger,
+
+		epiWeekVaccinationStatus: stores.NewVaccinationStatusService(firestoreClient, "covid_vaccination_status_epiweek_stats"),
+		epiWeekReportingDelays:   stores.NewReportingDelayService(firestoreClient, "covid_reporting_delay_epiweek_stats"),
+		epiWeekClusters:          stores.NewClusterService(firestoreClient, "covid_cluster_epiweek_stats"),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
 
 func enableCors() Middleware {
@@ -46,6 +104,7 @@ func enableCors() Middleware {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
 			w.Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, Referer, Connection, X-POE-Authorization")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Population-Version")
 			w.Header().Set("responseType", "*")
 			f(w, r)
 		}
@@ -57,6 +116,94 @@ func (s *Server) registerHandlers() {
 	h := NewChain(enableCors())
 	s.router.HandleFunc("/byYear/{year:[0-9]+}", h.Then(s.HandleFindYearStats)).
 		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/byMonth/{month}", h.Then(s.HandleFindMonthStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/deaths/byYear/{year:[0-9]+}", h.Then(s.HandleFindDeaths)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/deaths/byMonth/{month:[0-9]{4}-[0-9]{2}}", h.Then(s.HandleFindDeaths)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/deaths/byWeek/{week:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleFindDeaths)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/deaths/byEpiWeek/{epiWeek:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleFindDeaths)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/recoveries/byYear/{year:[0-9]+}", h.Then(s.HandleFindRecoveries)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/recoveries/byMonth/{month:[0-9]{4}-[0-9]{2}}", h.Then(s.HandleFindRecoveries)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/recoveries/byWeek/{week:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleFindRecoveries)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/recoveries/byEpiWeek/{epiWeek:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleFindRecoveries)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/active", h.Then(s.HandleActiveCases)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/alerts", h.Then(s.HandleAlerts)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/growth", h.Then(s.HandleGrowthRate)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/hospital", h.Then(s.HandleHospitalStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/rt", h.Then(s.HandleRt)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/tests", h.Then(s.HandleTestStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/compare", h.Then(s.HandleComparePeriods)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/contactTracing", h.Then(s.HandleContactTracingStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/vaccinationStatus/byYear/{year:[0-9]+}", h.Then(s.HandleVaccinationStatus)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/vaccinationStatus/byWeek/{week:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleVaccinationStatus)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/vaccinationStatus/byEpiYear/{epiYear:[0-9]+}", h.Then(s.HandleVaccinationStatus)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/vaccinationStatus/byEpiWeek/{epiWeek:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleVaccinationStatus)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/nowcast", h.Then(s.HandleNowcast)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/onset/byYear/{year:[0-9]+}", h.Then(s.HandleFindOnset)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/onset/byMonth/{month:[0-9]{4}-[0-9]{2}}", h.Then(s.HandleFindOnset)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/onset/byWeek/{week:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleFindOnset)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/onset/byEpiWeek/{epiWeek:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleFindOnset)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/reportingDelay/byYear/{year:[0-9]+}", h.Then(s.HandleReportingDelay)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/reportingDelay/byWeek/{week:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleReportingDelay)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/reportingDelay/byEpiYear/{epiYear:[0-9]+}", h.Then(s.HandleReportingDelay)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/reportingDelay/byEpiWeek/{epiWeek:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleReportingDelay)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/cases", h.Then(s.HandleCases)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/clusters/byYear/{year:[0-9]+}", h.Then(s.HandleClusterStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/clusters/byWeek/{week:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleClusterStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/clusters/byEpiYear/{epiYear:[0-9]+}", h.Then(s.HandleClusterStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/clusters/byEpiWeek/{epiWeek:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleClusterStats)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/cumulative", h.Then(s.HandleCumulative)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/daily", h.Then(s.HandleDailySeries)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/demographics/byDay/{day:[0-9]{4}-[0-9]{2}-[0-9]{2}}", h.Then(s.HandleDemographics)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/demographics/byWeek/{week:[0-9]{4}-[0-9]{1,2}}", h.Then(s.HandleDemographics)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/demographics/byMonth/{month:[0-9]{4}-[0-9]{2}}", h.Then(s.HandleDemographics)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/epiWeeks", h.Then(s.HandleEpiWeeks)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/forecasts", h.Then(s.HandleForecasts)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/waves", h.Then(s.HandleWaves)).
+		Methods(http.MethodOptions, http.MethodGet)
+	s.router.HandleFunc("/geojson/districts", h.Then(s.HandleDistrictGeoJSON)).
+		Methods(http.MethodOptions, http.MethodGet)
 }
 
 // Start boots up the server
@@ -0,0 +1,102 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+)
+
+// Alert periods
+const (
+	PeriodDay  = "day"
+	PeriodWeek = "week"
+)
+
+// Alert is the outcome of an aberration detection method on the cases of a
+// day, or of the week starting on Date, of an admin unit
+type Alert struct {
+	Date   *time.Time `json:"date"`
+	Period string     `json:"period"`
+	Method string     `json:"method"`
+	// Unit is a district or NationalUnit
+	Unit     string `json:"unit"`
+	Observed int    `json:"observed"`
+	// Expected and SD describe the baseline the count is compared to, made
+	// of BaselinePeriods days or weeks
+	Expected        float64 `json:"expected"`
+	SD              float64 `json:"sd"`
+	BaselinePeriods int     `json:"baselinePeriods"`
+	// UpperBound is the count above which the method signals
+	UpperBound float64 `json:"upperBound"`
+	// Score is the statistic of the method, which signals above Threshold
+	Score     float64 `json:"score"`
+	Threshold float64 `json:"threshold"`
+	Signal    bool    `json:"signal"`
+}
+
+// AlertService is a service for persisting and querying the aberration
+// detection outcomes
+type AlertService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewAlertService creates a new service
+func NewAlertService(db *Firestore, collection string) *AlertService {
+	return &AlertService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the outcomes, replacing earlier evaluations of the same
+// period, method and unit. Outcomes without a signal are kept as well so a
+// signal that disappears once late cases are reported is overwritten.
+func (a *AlertService) Save(ctx context.Context, alerts []Alert) error {
+	err := a.db.setInBatches(ctx, len(alerts), func(batch *fs.WriteBatch, i int) {
+		al := alerts[i]
+		id := fmt.Sprintf("%s_%s_%s_%s", al.Period, al.Date.Format("2006-01-02"), al.Method, al.Unit)
+		batch.Set(a.colRef.Doc(id), map[string]interface{}{
+			"date":            al.Date,
+			"period":          al.Period,
+			"method":          al.Method,
+			"unit":            al.Unit,
+			"observed":        al.Observed,
+			"expected":        al.Expected,
+			"sd":              al.SD,
+			"baselinePeriods": al.BaselinePeriods,
+			"upperBound":      al.UpperBound,
+			"score":           al.Score,
+			"threshold":       al.Threshold,
+			"signal":          al.Signal,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save alerts: %w", err)
+	}
+	return nil
+}
+
+// FindSignals retrieves the outcomes that signalled for the periods starting
+// from (inclusive) up to (exclusive) the given dates. The query needs the
+// composite index on signal and date created by `make deployIndexes`.
+func (a *AlertService) FindSignals(ctx context.Context, from, to time.Time) ([]Alert, error) {
+	var alerts []Alert
+	q := a.colRef.Query.Where("signal", "==", true).
+		Where("date", ">=", from).Where("date", "<", to).
+		OrderBy("date", fs.Asc)
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var al Alert
+		if err := doc.DataTo(&al); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		alerts = append(alerts, al)
+		return nil
+	})
+	if err != nil {
+		return alerts, fmt.Errorf("AlertService.FindSignals() error: %w", err)
+	}
+	return alerts, nil
+}
@@ -0,0 +1,87 @@
+package stores
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidBoundaries is returned when the boundaries file can not be used
+var ErrInvalidBoundaries = errors.New("invalid district boundaries")
+
+// Boundaries holds the boundary geometry of each district
+type Boundaries struct {
+	geometries map[District]json.RawMessage
+}
+
+// boundaryNameKeys are the feature properties checked, in order, for the
+// district name. They cover hand made files and common admin boundary sets.
+func boundaryNameKeys() []string {
+	return []string{"district", "name", "NAME_1", "ADM1_EN"}
+}
+
+// OpenBoundaries loads the district boundaries from a GeoJSON file
+func OpenBoundaries(path string) (*Boundaries, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("OpenBoundaries: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	return LoadBoundaries(f)
+}
+
+// LoadBoundaries reads a GeoJSON FeatureCollection with one feature per
+// district. Features are matched to a district by name.
+func LoadBoundaries(r io.Reader) (*Boundaries, error) {
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Properties map[string]interface{} `json:"properties"`
+			Geometry   json.RawMessage        `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("LoadBoundaries: failed to decode geojson: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("LoadBoundaries: %w: expected a FeatureCollection, got %q", ErrInvalidBoundaries, fc.Type)
+	}
+
+	b := &Boundaries{geometries: map[District]json.RawMessage{}}
+	for _, f := range fc.Features {
+		if d, ok := districtFromProperties(f.Properties); ok {
+			b.geometries[d] = f.Geometry
+		}
+	}
+	for _, d := range Districts() {
+		if _, ok := b.geometries[d]; !ok {
+			return nil, fmt.Errorf("LoadBoundaries: %w: no feature for %s", ErrInvalidBoundaries, d)
+		}
+	}
+	return b, nil
+}
+
+func districtFromProperties(props map[string]interface{}) (District, bool) {
+	for _, k := range boundaryNameKeys() {
+		name, ok := props[k].(string)
+		if !ok {
+			continue
+		}
+		for _, d := range Districts() {
+			if strings.EqualFold(strings.TrimSpace(name), string(d)) {
+				return d, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Geometry returns the GeoJSON geometry of the district
+func (b *Boundaries) Geometry(d District) json.RawMessage {
+	return b.geometries[d]
+}
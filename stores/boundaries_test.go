@@ -0,0 +1,34 @@
+package stores
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLoadBoundaries(t *testing.T) {
+	var features []string
+	for _, d := range Districts() {
+		features = append(features, fmt.Sprintf(
+			`{"type":"Feature","properties":{"NAME_1":%q},"geometry":{"type":"Point","coordinates":[-88.5,17.2]}}`,
+			strings.ToUpper(string(d)),
+		))
+	}
+	fc := `{"type":"FeatureCollection","features":[` + strings.Join(features, ",") + `]}`
+
+	b, err := LoadBoundaries(strings.NewReader(fc))
+	if err != nil {
+		t.Fatalf("LoadBoundaries failed: %v", err)
+	}
+	if g := b.Geometry(ow); len(g) == 0 {
+		t.Errorf("no geometry for %s", ow)
+	}
+}
+
+func TestLoadBoundaries_MissingDistrict(t *testing.T) {
+	fc := `{"type":"FeatureCollection","features":[{"type":"Feature","properties":{"name":"Cayo"},"geometry":null}]}`
+	if _, err := LoadBoundaries(strings.NewReader(fc)); !errors.Is(err, ErrInvalidBoundaries) {
+		t.Errorf("LoadBoundaries returned %v, want ErrInvalidBoundaries", err)
+	}
+}
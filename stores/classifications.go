@@ -0,0 +1,146 @@
+package stores
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// classificationPrefix is the prefix of the Go.Data case classification
+// reference data
+const classificationPrefix = "LNG_REFERENCE_DATA_CATEGORY_CASE_CLASSIFICATION_"
+
+// Go.Data case classifications
+const (
+	ClassificationConfirmed = classificationPrefix + "CONFIRMED"
+	ClassificationProbable  = classificationPrefix + "PROBABLE"
+	ClassificationSuspect   = classificationPrefix + "SUSPECT"
+)
+
+// ErrUnknownClassification is returned for an unsupported classification
+var ErrUnknownClassification = errors.New("unknown case classification")
+
+// Classifications returns the case classifications that can be tracked
+func Classifications() []string {
+	return []string{ClassificationConfirmed, ClassificationProbable, ClassificationSuspect}
+}
+
+// ClassificationName returns the short name of a classification, such as
+// "confirmed", as used in the stored stats and the API
+func ClassificationName(id string) string {
+	return strings.ToLower(strings.TrimPrefix(id, classificationPrefix))
+}
+
+// ParseClassifications parses comma separated classification names, such
+// as "confirmed,probable", into their Go.Data classifications
+func ParseClassifications(s string) ([]string, error) {
+	var ids []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		id := ""
+		for _, c := range Classifications() {
+			if ClassificationName(c) == name {
+				id = c
+			}
+		}
+		if id == "" {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownClassification, name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// CountCasesByClassification counts the cases reported on each date by
+// classification. Only the cases with a published classification make up the
+// total and the district counts. Every tracked classification is present in
+// the counts, even when zero.
+func CountCasesByClassification(cases []Case, tracked, published []string) []CaseCount {
+	isPublished := map[string]bool{}
+	for _, p := range published {
+		isPublished[p] = true
+	}
+
+	var counts []CaseCount
+	byDate := map[time.Time]int{}
+	for _, c := range cases {
+		if c.ReportingDate == nil {
+			continue
+		}
+		idx, ok := byDate[*c.ReportingDate]
+		if !ok {
+			d := *c.ReportingDate
+			cc := CaseCount{
+				ReportingDate:   &d,
+				Districts:       map[District]int{},
+				Classifications: map[string]int{},
+			}
+			for _, t := range tracked {
+				cc.Classifications[ClassificationName(t)] = 0
+			}
+			counts = append(counts, cc)
+			idx = len(counts) - 1
+			byDate[d] = idx
+		}
+		counts[idx].Classifications[ClassificationName(c.Classification)]++
+		if !isPublished[c.Classification] {
+			continue
+		}
+		counts[idx].Count++
+		if c.District != "" {
+			counts[idx].Districts[c.District]++
+		}
+	}
+	return counts
+}
+
+// FillCaseCounts returns the counts of every day from (inclusive) up to
+// (exclusive) the given dates, in date order. Counts are keyed by their UTC
+// day, adding up the counts that fall on the same day. Days without cases get
+// a zero count, with every tracked classification at zero, so saving them
+// clears the counts of a day whose cases were all reclassified or removed.
+func FillCaseCounts(counts []CaseCount, tracked []string, from, to time.Time) []CaseCount {
+	byDate := map[time.Time]CaseCount{}
+	for _, c := range counts {
+		if c.ReportingDate == nil {
+			continue
+		}
+		day := c.ReportingDate.UTC().Truncate(24 * time.Hour)
+		prev, ok := byDate[day]
+		if !ok {
+			d := day
+			prev = CaseCount{ReportingDate: &d, Districts: map[District]int{}}
+		}
+		prev.Count += c.Count
+		for d, n := range c.Districts {
+			prev.Districts[d] += n
+		}
+		if prev.Classifications == nil && c.Classifications != nil {
+			prev.Classifications = map[string]int{}
+		}
+		for cl, n := range c.Classifications {
+			prev.Classifications[cl] += n
+		}
+		byDate[day] = prev
+	}
+	var filled []CaseCount
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		c, ok := byDate[day]
+		if !ok {
+			d := day
+			c = CaseCount{
+				ReportingDate: &d,
+				Districts:     map[District]int{},
+			}
+			if len(tracked) > 0 {
+				c.Classifications = map[string]int{}
+			}
+			for _, t := range tracked {
+				c.Classifications[ClassificationName(t)] = 0
+			}
+		}
+		filled = append(filled, c)
+	}
+	return filled
+}
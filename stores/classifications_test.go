@@ -0,0 +1,67 @@
+package stores
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseClassifications(t *testing.T) {
+	ids, err := ParseClassifications("confirmed, Probable")
+	if err != nil {
+		t.Fatalf("ParseClassifications failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != ClassificationConfirmed || ids[1] != ClassificationProbable {
+		t.Errorf("unexpected classifications: %v", ids)
+	}
+	if _, err := ParseClassifications("confirmed,discarded"); !errors.Is(err, ErrUnknownClassification) {
+		t.Errorf("ParseClassifications returned %v, want ErrUnknownClassification", err)
+	}
+}
+
+func TestCountCasesByClassification(t *testing.T) {
+	day, _ := time.Parse(isoLayout, "2021-08-10")
+	cases := []Case{
+		{ReportingDate: &day, Classification: ClassificationConfirmed, District: cy},
+		{ReportingDate: &day, Classification: ClassificationConfirmed, District: cy},
+		{ReportingDate: &day, Classification: ClassificationProbable, District: to},
+	}
+
+	counts := CountCasesByClassification(cases, Classifications(), []string{ClassificationConfirmed})
+	if len(counts) != 1 {
+		t.Fatalf("expected counts for 1 day, got %d", len(counts))
+	}
+	c := counts[0]
+	if c.Count != 2 || c.Districts[cy] != 2 || c.Districts[to] != 0 {
+		t.Errorf("published totals should only count confirmed cases: %+v", c)
+	}
+	want := map[string]int{"confirmed": 2, "probable": 1, "suspect": 0}
+	for k, v := range want {
+		if n, ok := c.Classifications[k]; !ok || n != v {
+			t.Errorf("classification %s = %d, want %d", k, n, v)
+		}
+	}
+}
+
+func TestFillCaseCounts(t *testing.T) {
+	from, _ := time.Parse(isoLayout, "2021-08-09")
+	to, _ := time.Parse(isoLayout, "2021-08-12")
+	day, _ := time.Parse(isoLayout, "2021-08-10")
+	counts := []CaseCount{{ReportingDate: &day, Count: 3, Districts: map[District]int{cy: 3}}}
+
+	filled := FillCaseCounts(counts, Classifications(), from, to)
+	if len(filled) != 3 {
+		t.Fatalf("expected counts for 3 days, got %d", len(filled))
+	}
+	for i, want := range []int{0, 3, 0} {
+		if !filled[i].ReportingDate.Equal(from.AddDate(0, 0, i)) || filled[i].Count != want {
+			t.Errorf("day %d counted as %+v, want %d cases", i, filled[i], want)
+		}
+	}
+	if n, ok := filled[0].Classifications["confirmed"]; !ok || n != 0 {
+		t.Errorf("expected a zero confirmed count on an empty day, got %v", filled[0].Classifications)
+	}
+	if filled[0].Districts == nil {
+		t.Error("expected the districts of an empty day to be written")
+	}
+}
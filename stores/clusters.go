@@ -0,0 +1,250 @@
+package stores
+
+import (
+	"context"
+	"covidstats/series"
+	"fmt"
+	"sort"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func (m *Mongo) relationshipCollection() string {
+	return "relationship"
+}
+
+// RelationshipPerson is one end of a relationship
+type RelationshipPerson struct {
+	ID     string `bson:"id" json:"id"`
+	Source bool   `bson:"source" json:"source,omitempty"`
+	Target bool   `bson:"target" json:"target,omitempty"`
+}
+
+// Relationship links a source person to the person they exposed
+type Relationship struct {
+	Persons []RelationshipPerson `bson:"persons" json:"persons"`
+}
+
+// Ends returns the source and target of the relationship
+func (r Relationship) Ends() (source, target string) {
+	for _, p := range r.Persons {
+		switch {
+		case p.Source:
+			source = p.ID
+		case p.Target:
+			target = p.ID
+		}
+	}
+	return source, target
+}
+
+// FindRelationships finds all the relationships of an outbreak
+func (m *Mongo) FindRelationships(ctx context.Context, outbreakID string) ([]Relationship, error) {
+	collection := m.Client.Database(m.Database).Collection(m.relationshipCollection())
+	filter := bson.M{
+		"outbreakId": outbreakID,
+		"deleted":    false,
+	}
+
+	var relationships []Relationship
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return relationships, MongoQueryErr{
+			Reason: fmt.Sprintf("failed to retrieve relationships for outbreak %s", outbreakID),
+			Inner:  err,
+		}
+	}
+	if err := cursor.All(ctx, &relationships); err != nil {
+		return relationships, MongoQueryErr{
+			Reason: fmt.Sprintf("error executing query for relationships in outbreak %s", outbreakID),
+			Inner:  err,
+		}
+	}
+
+	return relationships, nil
+}
+
+// ClusterStats summarises the transmission chains of the cases reported in
+// a week. A cluster is a group of two or more cases linked, directly or
+// through other cases, by relationships.
+type ClusterStats struct {
+	Week     string `json:"week"`
+	Year     int    `json:"year"`
+	NewCases int    `json:"newCases"`
+	// LinkedCases are the new cases with a known source
+	LinkedCases int `json:"linkedCases"`
+	// SourceCases are the new cases that are the source of other cases,
+	// and SecondaryCases the cases they are the source of
+	SourceCases    int `json:"sourceCases"`
+	SecondaryCases int `json:"secondaryCases"`
+	// ActiveClusters are the clusters with a new case, of which
+	// NewClusters started in the week
+	ActiveClusters int `json:"activeClusters"`
+	NewClusters    int `json:"newClusters"`
+	// The sizes of the active clusters
+	MedianClusterSize  float64 `json:"medianClusterSize"`
+	LargestClusterSize int     `json:"largestClusterSize"`
+}
+
+// ClusterStatsByWeek computes the cluster statistics of each of the weeks.
+// Only the relationships between two of the cases are considered.
+func ClusterStatsByWeek(cases []Case, relationships []Relationship, weeks Weeks) []ClusterStats {
+	byID := map[string]Case{}
+	parent := map[string]string{}
+	for _, c := range cases {
+		if c.ID == "" || c.ReportingDate == nil {
+			continue
+		}
+		byID[c.ID] = c
+		parent[c.ID] = c.ID
+	}
+	var find func(string) string
+	find = func(id string) string {
+		if parent[id] != id {
+			parent[id] = find(parent[id])
+		}
+		return parent[id]
+	}
+
+	hasSource := map[string]bool{}
+	secondary := map[string]map[string]bool{}
+	for _, r := range relationships {
+		source, target := r.Ends()
+		if _, ok := byID[source]; !ok {
+			continue
+		}
+		if _, ok := byID[target]; !ok || source == target {
+			continue
+		}
+		hasSource[target] = true
+		if secondary[source] == nil {
+			secondary[source] = map[string]bool{}
+		}
+		secondary[source][target] = true
+		parent[find(source)] = find(target)
+	}
+
+	sizes := map[string]int{}
+	started := map[string]time.Time{}
+	for id, c := range byID {
+		root := find(id)
+		sizes[root]++
+		if s, ok := started[root]; !ok || c.ReportingDate.Before(s) {
+			started[root] = *c.ReportingDate
+		}
+	}
+
+	var result []ClusterStats
+	byWeek := map[string]int{}
+	active := map[string]map[string]bool{}
+	weekStarts := map[string]time.Time{}
+	for id, c := range byID {
+		week, yr, start := weeks.Of(*c.ReportingDate)
+		idx, ok := byWeek[week]
+		if !ok {
+			weekStarts[week] = start
+			result = append(result, ClusterStats{Week: week, Year: yr})
+			idx = len(result) - 1
+			byWeek[week] = idx
+			active[week] = map[string]bool{}
+		}
+		st := &result[idx]
+		st.NewCases++
+		if hasSource[id] {
+			st.LinkedCases++
+		}
+		if n := len(secondary[id]); n > 0 {
+			st.SourceCases++
+			st.SecondaryCases += n
+		}
+		if root := find(id); sizes[root] > 1 {
+			active[week][root] = true
+		}
+	}
+
+	for i, st := range result {
+		var clusterSizes []float64
+		for root := range active[st.Week] {
+			clusterSizes = append(clusterSizes, float64(sizes[root]))
+			if week, _, _ := weeks.Of(started[root]); week == st.Week {
+				result[i].NewClusters++
+			}
+		}
+		sort.Float64s(clusterSizes)
+		result[i].ActiveClusters = len(clusterSizes)
+		result[i].MedianClusterSize = series.Quantile(clusterSizes, 0.5)
+		if len(clusterSizes) > 0 {
+			result[i].LargestClusterSize = int(clusterSizes[len(clusterSizes)-1])
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return weekStarts[result[i].Week].Before(weekStarts[result[j].Week])
+	})
+	return result
+}
+
+// ClusterService is a service for persisting and querying the weekly
+// cluster statistics
+type ClusterService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewClusterService creates a new service
+func NewClusterService(db *Firestore, collection string) *ClusterService {
+	return &ClusterService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the weekly cluster statistics, replacing those of each week
+func (c *ClusterService) Save(ctx context.Context, stats []ClusterStats) error {
+	err := c.db.setInBatches(ctx, len(stats), func(batch *fs.WriteBatch, i int) {
+		st := stats[i]
+		batch.Set(c.colRef.Doc(st.Week), map[string]interface{}{
+			"week":               st.Week,
+			"year":               st.Year,
+			"newCases":           st.NewCases,
+			"linkedCases":        st.LinkedCases,
+			"sourceCases":        st.SourceCases,
+			"secondaryCases":     st.SecondaryCases,
+			"activeClusters":     st.ActiveClusters,
+			"newClusters":        st.NewClusters,
+			"medianClusterSize":  st.MedianClusterSize,
+			"largestClusterSize": st.LargestClusterSize,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save cluster stats: %w", err)
+	}
+	return nil
+}
+
+// FindByYear retrieves the cluster statistics of the weeks in a year
+func (c *ClusterService) FindByYear(ctx context.Context, year int) ([]ClusterStats, error) {
+	return c.find(ctx, c.colRef.Query.Where("year", "==", year))
+}
+
+// FindByWeek retrieves the cluster statistics of a week (yyyy-w)
+func (c *ClusterService) FindByWeek(ctx context.Context, week string) ([]ClusterStats, error) {
+	return c.find(ctx, c.colRef.Query.Where("week", "==", week))
+}
+
+func (c *ClusterService) find(ctx context.Context, q fs.Query) ([]ClusterStats, error) {
+	var stats []ClusterStats
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var st ClusterStats
+		if err := doc.DataTo(&st); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		stats = append(stats, st)
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("ClusterService.find() error: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,42 @@
+package stores
+
+import "testing"
+
+func TestClusterStatsByWeek(t *testing.T) {
+	link := func(source, target string) Relationship {
+		return Relationship{Persons: []RelationshipPerson{
+			{ID: target, Target: true},
+			{ID: source, Source: true},
+		}}
+	}
+	cases := []Case{
+		{ID: "a", ReportingDate: day("2021-08-02")},
+		{ID: "b", ReportingDate: day("2021-08-04")},
+		{ID: "c", ReportingDate: day("2021-08-10")},
+		{ID: "d", ReportingDate: day("2021-08-11")},
+		{ID: "e", ReportingDate: day("2021-08-11")},
+		{ID: "f", ReportingDate: day("2021-08-12")},
+	}
+	relationships := []Relationship{
+		link("a", "b"),
+		link("a", "c"),
+		link("e", "f"),
+		link("contact", "d"),
+	}
+
+	stats := ClusterStatsByWeek(cases, relationships, ISOWeeks)
+	if len(stats) != 2 {
+		t.Fatalf("expected 2 weeks, got %d", len(stats))
+	}
+	want := []ClusterStats{
+		{Week: "2021-31", Year: 2021, NewCases: 2, LinkedCases: 1, SourceCases: 1, SecondaryCases: 2,
+			ActiveClusters: 1, NewClusters: 1, MedianClusterSize: 3, LargestClusterSize: 3},
+		{Week: "2021-32", Year: 2021, NewCases: 4, LinkedCases: 2, SourceCases: 1, SecondaryCases: 1,
+			ActiveClusters: 2, NewClusters: 1, MedianClusterSize: 2.5, LargestClusterSize: 3},
+	}
+	for i, w := range want {
+		if stats[i] != w {
+			t.Errorf("week %s: got %+v, want %+v", w.Week, stats[i], w)
+		}
+	}
+}
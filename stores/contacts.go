@@ -0,0 +1,230 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Go.Data contact reference data
+const (
+	PersonTypeContact = "LNG_REFERENCE_DATA_CATEGORY_PERSON_TYPE_CONTACT"
+	FollowUpSeenOK    = "LNG_REFERENCE_DATA_CONTACT_DAILY_FOLLOW_UP_STATUS_TYPE_SEEN_OK"
+	FollowUpSeenNotOK = "LNG_REFERENCE_DATA_CONTACT_DAILY_FOLLOW_UP_STATUS_TYPE_SEEN_NOT_OK"
+)
+
+func (m *Mongo) followUpCollection() string {
+	return "followUp"
+}
+
+// FollowUpPeriod is the period a contact is followed up
+type FollowUpPeriod struct {
+	StartDate *time.Time `bson:"startDate" json:"startDate,omitempty"`
+	EndDate   *time.Time `bson:"endDate" json:"endDate,omitempty"`
+}
+
+// Contact is a contact of a case as recorded in Go.Data
+type Contact struct {
+	ReportingDate *time.Time      `bson:"dateOfReporting" json:"reportingDate"`
+	FollowUp      *FollowUpPeriod `bson:"followUp" json:"followUp,omitempty"`
+}
+
+// FollowUp is a daily follow up of a contact
+type FollowUp struct {
+	Date     *time.Time `bson:"date" json:"date"`
+	StatusID string     `bson:"statusId" json:"statusId"`
+}
+
+// Seen returns whether the contact was seen during the follow up
+func (f FollowUp) Seen() bool {
+	return f.StatusID == FollowUpSeenOK || f.StatusID == FollowUpSeenNotOK
+}
+
+// FindContacts finds the contacts registered, or followed up, from
+// (inclusive) up to (exclusive) the given dates
+func (m *Mongo) FindContacts(ctx context.Context, outbreakID string, from, to time.Time) ([]Contact, error) {
+	collection := m.Client.Database(m.Database).Collection(m.personCollection())
+	filter := bson.M{
+		"outbreakId": outbreakID,
+		"type":       PersonTypeContact,
+		"deleted":    false,
+		"$or": bson.A{
+			bson.M{"dateOfReporting": bson.M{"$gte": from, "$lt": to}},
+			bson.M{"followUp.startDate": bson.M{"$lt": to}, "followUp.endDate": bson.M{"$gte": from}},
+		},
+	}
+
+	var contacts []Contact
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return contacts, MongoQueryErr{
+			Reason: fmt.Sprintf("failed to retrieve contacts for outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+	if err := cursor.All(ctx, &contacts); err != nil {
+		return contacts, MongoQueryErr{
+			Reason: fmt.Sprintf("error executing query for contacts in outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+
+	return contacts, nil
+}
+
+// FindFollowUps finds the daily follow ups from (inclusive) up to
+// (exclusive) the given dates
+func (m *Mongo) FindFollowUps(ctx context.Context, outbreakID string, from, to time.Time) ([]FollowUp, error) {
+	collection := m.Client.Database(m.Database).Collection(m.followUpCollection())
+	filter := bson.M{
+		"outbreakId": outbreakID,
+		"deleted":    false,
+		"date":       bson.M{"$gte": from, "$lt": to},
+	}
+
+	var followUps []FollowUp
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return followUps, MongoQueryErr{
+			Reason: fmt.Sprintf("failed to retrieve follow ups for outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+	if err := cursor.All(ctx, &followUps); err != nil {
+		return followUps, MongoQueryErr{
+			Reason: fmt.Sprintf("error executing query for follow ups in outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+
+	return followUps, nil
+}
+
+// ContactTracingCount holds the contact tracing activity on a date
+type ContactTracingCount struct {
+	Date *time.Time `json:"date"`
+	// NewContacts are the contacts registered on the date
+	NewContacts int `json:"newContacts"`
+	// UnderFollowUp are the contacts whose follow up period includes the date
+	UnderFollowUp int `json:"underFollowUp"`
+	// FollowUps are the follow ups due on the date, of which Seen were seen
+	FollowUps int `json:"followUps"`
+	Seen      int `json:"seen"`
+	// NewCases are the cases reported on the date, of which
+	// NewCasesFromContacts were previously listed as contacts
+	NewCases             int `json:"newCases"`
+	NewCasesFromContacts int `json:"newCasesFromContacts"`
+}
+
+// CountContactTracing counts the contact tracing activity for every day
+// from (inclusive) up to (exclusive) to
+func CountContactTracing(contacts []Contact, followUps []FollowUp, cases []Case, from, to time.Time) []ContactTracingCount {
+	var counts []ContactTracingCount
+	index := map[time.Time]int{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		d := day
+		index[d] = len(counts)
+		counts = append(counts, ContactTracingCount{Date: &d})
+	}
+	indexOf := func(t *time.Time) (int, bool) {
+		if t == nil {
+			return 0, false
+		}
+		idx, ok := index[t.UTC().Truncate(24*time.Hour)]
+		return idx, ok
+	}
+
+	for _, c := range contacts {
+		if idx, ok := indexOf(c.ReportingDate); ok {
+			counts[idx].NewContacts++
+		}
+		if c.FollowUp == nil || c.FollowUp.StartDate == nil || c.FollowUp.EndDate == nil {
+			continue
+		}
+		start := c.FollowUp.StartDate.UTC().Truncate(24 * time.Hour)
+		end := c.FollowUp.EndDate.UTC().Truncate(24 * time.Hour)
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			if idx, ok := index[day]; ok {
+				counts[idx].UnderFollowUp++
+			}
+		}
+	}
+	for _, f := range followUps {
+		if idx, ok := indexOf(f.Date); ok {
+			counts[idx].FollowUps++
+			if f.Seen() {
+				counts[idx].Seen++
+			}
+		}
+	}
+	for _, c := range cases {
+		if idx, ok := indexOf(c.ReportingDate); ok {
+			counts[idx].NewCases++
+			if c.WasContact {
+				counts[idx].NewCasesFromContacts++
+			}
+		}
+	}
+	return counts
+}
+
+// ContactTracingService is a service for persisting and querying the
+// contact tracing statistics
+type ContactTracingService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewContactTracingService creates a new service
+func NewContactTracingService(db *Firestore, collection string) *ContactTracingService {
+	return &ContactTracingService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the contact tracing statistics, replacing those of each date
+func (c *ContactTracingService) Save(ctx context.Context, counts []ContactTracingCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	err := c.db.setInBatches(ctx, len(counts), func(batch *fs.WriteBatch, i int) {
+		ct := counts[i]
+		ref := c.colRef.Doc(ct.Date.Format("2006-01-02"))
+		data := periodFields(*ct.Date)
+		data["date"] = ct.Date
+		data["newContacts"] = ct.NewContacts
+		data["underFollowUp"] = ct.UnderFollowUp
+		data["followUps"] = ct.FollowUps
+		data["seen"] = ct.Seen
+		data["newCases"] = ct.NewCases
+		data["newCasesFromContacts"] = ct.NewCasesFromContacts
+		batch.Set(ref, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save contact tracing stats: %w", err)
+	}
+	return nil
+}
+
+// FindByDateRange retrieves the contact tracing statistics from (inclusive)
+// up to (exclusive) the given dates
+func (c *ContactTracingService) FindByDateRange(ctx context.Context, from, to time.Time) ([]ContactTracingCount, error) {
+	var counts []ContactTracingCount
+	q := c.colRef.Query.Where("date", ">=", from).Where("date", "<", to).OrderBy("date", fs.Asc)
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var ct ContactTracingCount
+		if err := doc.DataTo(&ct); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		counts = append(counts, ct)
+		return nil
+	})
+	if err != nil {
+		return counts, fmt.Errorf("ContactTracingService.FindByDateRange() error: %w", err)
+	}
+	return counts, nil
+}
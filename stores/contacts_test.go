@@ -0,0 +1,34 @@
+package stores
+
+import "testing"
+
+func TestCountContactTracing(t *testing.T) {
+	contacts := []Contact{
+		{ReportingDate: day("2021-08-01"), FollowUp: &FollowUpPeriod{StartDate: day("2021-08-02"), EndDate: day("2021-08-15")}},
+		{ReportingDate: day("2021-07-20"), FollowUp: &FollowUpPeriod{StartDate: day("2021-07-21"), EndDate: day("2021-08-02")}},
+	}
+	followUps := []FollowUp{
+		{Date: day("2021-08-02"), StatusID: FollowUpSeenOK},
+		{Date: day("2021-08-02"), StatusID: "LNG_REFERENCE_DATA_CONTACT_DAILY_FOLLOW_UP_STATUS_TYPE_NOT_SEEN"},
+	}
+	cases := []Case{
+		{ReportingDate: day("2021-08-03"), WasContact: true},
+		{ReportingDate: day("2021-08-03")},
+	}
+
+	counts := CountContactTracing(contacts, followUps, cases, *day("2021-08-01"), *day("2021-08-04"))
+	if len(counts) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(counts))
+	}
+	want := []ContactTracingCount{
+		{NewContacts: 1, UnderFollowUp: 1},
+		{UnderFollowUp: 2, FollowUps: 2, Seen: 1},
+		{UnderFollowUp: 1, NewCases: 2, NewCasesFromContacts: 1},
+	}
+	for i, w := range want {
+		w.Date = counts[i].Date
+		if counts[i] != w {
+			t.Errorf("%s: got %+v, want %+v", counts[i].Date.Format(isoLayout), counts[i], w)
+		}
+	}
+}
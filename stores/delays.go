@@ -0,0 +1,158 @@
+package stores
+
+import (
+	"context"
+	"covidstats/series"
+	"fmt"
+	"sort"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+)
+
+// delayHistogramDays is the number of histogram buckets of one day. Longer
+// delays are counted in a final bucket.
+const delayHistogramDays = 14
+
+// CountCasesByOnset counts the cases on each date of onset, keeping a per
+// district count alongside the national total. The count's ReportingDate
+// holds the date of onset, truncated to the UTC day.
+func CountCasesByOnset(cases []Case) []CaseCount {
+	return countCases(cases, func(c Case) *time.Time {
+		if c.OnsetDate == nil {
+			return nil
+		}
+		day := c.OnsetDate.UTC().Truncate(24 * time.Hour)
+		return &day
+	})
+}
+
+// ReportingDelay is the distribution of the days from onset to reporting of
+// the cases reported in a week
+type ReportingDelay struct {
+	Week  string `json:"week"`
+	Year  int    `json:"year"`
+	Cases int    `json:"cases"`
+	// MissingOnset are the cases without a date of onset, and InvalidOnset
+	// those whose onset is after the reporting date. Neither are part of
+	// the distribution.
+	MissingOnset int     `json:"missingOnset"`
+	InvalidOnset int     `json:"invalidOnset"`
+	Median       float64 `json:"median"`
+	Q1           float64 `json:"q1"`
+	Q3           float64 `json:"q3"`
+	// Histogram counts the cases by delay in days. The last bucket holds
+	// the delays of 14 days or more.
+	Histogram []int `json:"histogram"`
+}
+
+// ReportingDelaysByWeek computes the reporting delay distribution of the
+// cases reported in each of the weeks
+func ReportingDelaysByWeek(cases []Case, weeks Weeks) []ReportingDelay {
+	var result []ReportingDelay
+	delays := map[string][]float64{}
+	byWeek := map[string]int{}
+	for _, c := range cases {
+		if c.ReportingDate == nil {
+			continue
+		}
+		week, yr, _ := weeks.Of(*c.ReportingDate)
+		idx, ok := byWeek[week]
+		if !ok {
+			result = append(result, ReportingDelay{Week: week, Year: yr, Histogram: make([]int, delayHistogramDays+1)})
+			idx = len(result) - 1
+			byWeek[week] = idx
+		}
+		r := &result[idx]
+		r.Cases++
+		if c.OnsetDate == nil {
+			r.MissingOnset++
+			continue
+		}
+		days := int(c.ReportingDate.Sub(c.OnsetDate.UTC().Truncate(24*time.Hour)).Hours() / 24)
+		if days < 0 {
+			r.InvalidOnset++
+			continue
+		}
+		delays[week] = append(delays[week], float64(days))
+		if days > delayHistogramDays {
+			days = delayHistogramDays
+		}
+		r.Histogram[days]++
+	}
+
+	for i, r := range result {
+		d := delays[r.Week]
+		sort.Float64s(d)
+		result[i].Q1 = series.Quantile(d, 0.25)
+		result[i].Median = series.Quantile(d, 0.5)
+		result[i].Q3 = series.Quantile(d, 0.75)
+	}
+	return result
+}
+
+// ReportingDelayService is a service for persisting and querying the
+// weekly reporting delays
+type ReportingDelayService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewReportingDelayService creates a new service
+func NewReportingDelayService(db *Firestore, collection string) *ReportingDelayService {
+	return &ReportingDelayService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the weekly reporting delays, replacing those of each week
+func (r *ReportingDelayService) Save(ctx context.Context, delays []ReportingDelay) error {
+	if len(delays) == 0 {
+		return nil
+	}
+	err := r.db.setInBatches(ctx, len(delays), func(batch *fs.WriteBatch, i int) {
+		d := delays[i]
+		batch.Set(r.colRef.Doc(d.Week), map[string]interface{}{
+			"week":         d.Week,
+			"year":         d.Year,
+			"cases":        d.Cases,
+			"missingOnset": d.MissingOnset,
+			"invalidOnset": d.InvalidOnset,
+			"median":       d.Median,
+			"q1":           d.Q1,
+			"q3":           d.Q3,
+			"histogram":    d.Histogram,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save reporting delays: %w", err)
+	}
+	return nil
+}
+
+// FindByYear retrieves the reporting delays of the weeks in a year
+func (r *ReportingDelayService) FindByYear(ctx context.Context, year int) ([]ReportingDelay, error) {
+	return r.find(ctx, r.colRef.Query.Where("year", "==", year))
+}
+
+// FindByWeek retrieves the reporting delays of a week (yyyy-w)
+func (r *ReportingDelayService) FindByWeek(ctx context.Context, week string) ([]ReportingDelay, error) {
+	return r.find(ctx, r.colRef.Query.Where("week", "==", week))
+}
+
+func (r *ReportingDelayService) find(ctx context.Context, q fs.Query) ([]ReportingDelay, error) {
+	var delays []ReportingDelay
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var d ReportingDelay
+		if err := doc.DataTo(&d); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		delays = append(delays, d)
+		return nil
+	})
+	if err != nil {
+		return delays, fmt.Errorf("ReportingDelayService.find() error: %w", err)
+	}
+	return delays, nil
+}
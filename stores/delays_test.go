@@ -0,0 +1,58 @@
+package stores
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountCasesByOnset(t *testing.T) {
+	morning, _ := time.Parse(time.RFC3339, "2021-08-03T08:30:00Z")
+	evening, _ := time.Parse(time.RFC3339, "2021-08-03T21:15:00Z")
+	cases := []Case{
+		{OnsetDate: &morning, District: cy},
+		{OnsetDate: &evening, District: cy},
+		{},
+	}
+
+	counts := CountCasesByOnset(cases)
+	if len(counts) != 1 {
+		t.Fatalf("expected onsets on 1 day, got %d", len(counts))
+	}
+	if want, _ := time.Parse(isoLayout, "2021-08-03"); !counts[0].ReportingDate.Equal(want) {
+		t.Errorf("onsets counted on %v, want %v", counts[0].ReportingDate, want)
+	}
+	if counts[0].Count != 2 || counts[0].Districts[cy] != 2 {
+		t.Errorf("unexpected onset count: %+v", counts[0])
+	}
+}
+
+func TestReportingDelaysByWeek(t *testing.T) {
+	reported := day("2021-08-12")
+	cases := []Case{
+		{ReportingDate: reported, OnsetDate: day("2021-08-11")},
+		{ReportingDate: reported, OnsetDate: day("2021-08-09")},
+		{ReportingDate: reported, OnsetDate: day("2021-08-07")},
+		{ReportingDate: reported, OnsetDate: day("2021-07-01")},
+		{ReportingDate: reported},
+		{ReportingDate: reported, OnsetDate: day("2021-08-13")},
+		{ReportingDate: day("2021-08-16"), OnsetDate: day("2021-08-16")},
+	}
+
+	delays := ReportingDelaysByWeek(cases, ISOWeeks)
+	if len(delays) != 2 {
+		t.Fatalf("expected 2 weeks, got %d", len(delays))
+	}
+	d := delays[0]
+	if d.Week != "2021-32" || d.Cases != 6 || d.MissingOnset != 1 || d.InvalidOnset != 1 {
+		t.Errorf("unexpected week summary: %+v", d)
+	}
+	if d.Median != 4 || d.Q1 != 2.5 || d.Q3 != 14.25 {
+		t.Errorf("median %v (q1 %v, q3 %v), want 4 (2.5, 14.25)", d.Median, d.Q1, d.Q3)
+	}
+	if d.Histogram[1] != 1 || d.Histogram[3] != 1 || d.Histogram[5] != 1 || d.Histogram[delayHistogramDays] != 1 {
+		t.Errorf("unexpected histogram: %v", d.Histogram)
+	}
+	if delays[1].Histogram[0] != 1 {
+		t.Errorf("same day reporting should have no delay: %v", delays[1].Histogram)
+	}
+}
@@ -0,0 +1,252 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+)
+
+// Go.Data gender reference data
+const (
+	genderMale   = "LNG_REFERENCE_DATA_CATEGORY_GENDER_MALE"
+	genderFemale = "LNG_REFERENCE_DATA_CATEGORY_GENDER_FEMALE"
+)
+
+// Unknown is the group for cases missing the data to be grouped
+const Unknown = "unknown"
+
+// ErrInvalidAgeBand is returned when age bands can not be parsed
+var ErrInvalidAgeBand = errors.New("invalid age band")
+
+// AgeBand is an age group, in completed years. A Max of -1 means no upper
+// bound.
+type AgeBand struct {
+	Label string
+	Min   int
+	Max   int
+}
+
+// DefaultAgeBands are the age bands used in the bulletins
+func DefaultAgeBands() []AgeBand {
+	bands, _ := ParseAgeBands("0-4,5-17,18-39,40-59,60+")
+	return bands
+}
+
+// ParseAgeBands parses comma separated age bands, such as "0-4,5-17,60+"
+func ParseAgeBands(s string) ([]AgeBand, error) {
+	var bands []AgeBand
+	for _, b := range strings.Split(s, ",") {
+		b = strings.TrimSpace(b)
+		band := AgeBand{Label: b, Max: -1}
+		var err error
+		if strings.HasSuffix(b, "+") {
+			band.Min, err = strconv.Atoi(strings.TrimSuffix(b, "+"))
+		} else {
+			bounds := strings.SplitN(b, "-", 2)
+			if len(bounds) != 2 {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidAgeBand, b)
+			}
+			band.Min, err = strconv.Atoi(bounds[0])
+			if err == nil {
+				band.Max, err = strconv.Atoi(bounds[1])
+			}
+		}
+		if err != nil || band.Min < 0 || (band.Max != -1 && band.Max < band.Min) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAgeBand, b)
+		}
+		bands = append(bands, band)
+	}
+	return bands, nil
+}
+
+// ageBand returns the label of the band the age falls in
+func ageBand(bands []AgeBand, years int) string {
+	for _, b := range bands {
+		if years >= b.Min && (b.Max == -1 || years <= b.Max) {
+			return b.Label
+		}
+	}
+	return Unknown
+}
+
+// AgeOn returns the age of the case in completed years on the given date.
+// The recorded age is preferred over the date of birth.
+func (c Case) AgeOn(date time.Time) (int, bool) {
+	if c.Age != nil && (c.Age.Years > 0 || c.Age.Months > 0) {
+		return c.Age.Years, true
+	}
+	if c.DOB == nil || c.DOB.After(date) {
+		return 0, false
+	}
+	years := date.Year() - c.DOB.Year()
+	if date.Month() < c.DOB.Month() || (date.Month() == c.DOB.Month() && date.Day() < c.DOB.Day()) {
+		years--
+	}
+	return years, true
+}
+
+// Sex returns male, female or unknown
+func (c Case) Sex() string {
+	switch c.Gender {
+	case genderMale:
+		return "male"
+	case genderFemale:
+		return "female"
+	default:
+		return Unknown
+	}
+}
+
+// DemographicCount is the number of cases reported on a date by age group
+// and by sex
+type DemographicCount struct {
+	ReportingDate *time.Time     `json:"reportingDate"`
+	AgeGroups     map[string]int `json:"ageGroups"`
+	Sex           map[string]int `json:"sex"`
+}
+
+// CountCasesByDemographics counts the cases reported on each date by age band
+// and by sex
+func CountCasesByDemographics(cases []Case, bands []AgeBand) []DemographicCount {
+	var counts []DemographicCount
+	byDate := map[time.Time]int{}
+	for _, c := range cases {
+		if c.ReportingDate == nil {
+			continue
+		}
+		idx, ok := byDate[*c.ReportingDate]
+		if !ok {
+			d := *c.ReportingDate
+			counts = append(counts, DemographicCount{
+				ReportingDate: &d,
+				AgeGroups:     map[string]int{},
+				Sex:           map[string]int{},
+			})
+			idx = len(counts) - 1
+			byDate[d] = idx
+		}
+		group := Unknown
+		if years, ok := c.AgeOn(*c.ReportingDate); ok {
+			group = ageBand(bands, years)
+		}
+		counts[idx].AgeGroups[group]++
+		counts[idx].Sex[c.Sex()]++
+	}
+	return counts
+}
+
+// FillDemographicCounts returns the counts of every day from (inclusive) up
+// to (exclusive) the given dates, in date order. Days without cases get empty
+// groups, so saving them clears the counts of a day left without cases.
+func FillDemographicCounts(counts []DemographicCount, from, to time.Time) []DemographicCount {
+	byDate := map[time.Time]DemographicCount{}
+	for _, c := range counts {
+		if c.ReportingDate != nil {
+			byDate[c.ReportingDate.UTC().Truncate(24*time.Hour)] = c
+		}
+	}
+	var filled []DemographicCount
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		c, ok := byDate[day]
+		if !ok {
+			d := day
+			c = DemographicCount{
+				ReportingDate: &d,
+				AgeGroups:     map[string]int{},
+				Sex:           map[string]int{},
+			}
+		}
+		filled = append(filled, c)
+	}
+	return filled
+}
+
+// DemographicsService is a service for persisting and querying the
+// demographic breakdown of cases
+type DemographicsService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewDemographicsService creates a new service
+func NewDemographicsService(db *Firestore, collection string) *DemographicsService {
+	return &DemographicsService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the demographic counts. Each date is replaced so groups
+// that dropped to zero are removed.
+func (d *DemographicsService) Save(ctx context.Context, counts []DemographicCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	err := d.db.setInBatches(ctx, len(counts), func(batch *fs.WriteBatch, i int) {
+		c := counts[i]
+		ref := d.colRef.Doc(c.ReportingDate.Format("2006-01-02"))
+		data := periodFields(*c.ReportingDate)
+		data["reportingDate"] = c.ReportingDate
+		data["ageGroups"] = c.AgeGroups
+		data["sex"] = c.Sex
+		batch.Set(ref, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save demographics: %w", err)
+	}
+	return nil
+}
+
+// Demographics is the demographic breakdown of the cases for a period
+type Demographics struct {
+	Period    string         `json:"period"`
+	Total     int            `json:"total"`
+	AgeGroups map[string]int `json:"ageGroups"`
+	Sex       map[string]int `json:"sex"`
+}
+
+// FindByDay retrieves the breakdown for a day
+func (d *DemographicsService) FindByDay(ctx context.Context, day time.Time) (Demographics, error) {
+	return d.find(ctx, day.Format("2006-01-02"), d.colRef.Query.Where("reportingDate", "==", day))
+}
+
+// FindByWeek retrieves the breakdown for an ISO week (yyyy-w)
+func (d *DemographicsService) FindByWeek(ctx context.Context, week string) (Demographics, error) {
+	return d.find(ctx, week, d.colRef.Query.Where("week", "==", week))
+}
+
+// FindByMonth retrieves the breakdown for a month (yyyy-mm)
+func (d *DemographicsService) FindByMonth(ctx context.Context, month string) (Demographics, error) {
+	return d.find(ctx, month, d.colRef.Query.Where("month", "==", month))
+}
+
+func (d *DemographicsService) find(ctx context.Context, period string, q fs.Query) (Demographics, error) {
+	result := Demographics{
+		Period:    period,
+		AgeGroups: map[string]int{},
+		Sex:       map[string]int{},
+	}
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var c DemographicCount
+		if err := doc.DataTo(&c); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		for g, n := range c.AgeGroups {
+			result.AgeGroups[g] += n
+			result.Total += n
+		}
+		for s, n := range c.Sex {
+			result.Sex[s] += n
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("DemographicsService.find(%s) error: %w", period, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package stores
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseAgeBands(t *testing.T) {
+	bands := DefaultAgeBands()
+	tests := []struct {
+		age  int
+		want string
+	}{
+		{0, "0-4"},
+		{4, "0-4"},
+		{5, "5-17"},
+		{39, "18-39"},
+		{59, "40-59"},
+		{60, "60+"},
+		{101, "60+"},
+	}
+	for _, tt := range tests {
+		if got := ageBand(bands, tt.age); got != tt.want {
+			t.Errorf("ageBand(%d) = %s, want %s", tt.age, got, tt.want)
+		}
+	}
+
+	for _, s := range []string{"0-", "a-4", "10-5", "x+"} {
+		if _, err := ParseAgeBands(s); !errors.Is(err, ErrInvalidAgeBand) {
+			t.Errorf("ParseAgeBands(%q) returned %v, want ErrInvalidAgeBand", s, err)
+		}
+	}
+}
+
+func TestCountCasesByDemographics(t *testing.T) {
+	day, _ := time.Parse(isoLayout, "2021-08-10")
+	dob, _ := time.Parse(isoLayout, "2016-08-11")
+	cases := []Case{
+		{ReportingDate: &day, Age: &Age{Years: 34}, Gender: genderFemale},
+		{ReportingDate: &day, DOB: &dob, Gender: genderMale},
+		{ReportingDate: &day},
+	}
+
+	counts := CountCasesByDemographics(cases, DefaultAgeBands())
+	if len(counts) != 1 {
+		t.Fatalf("expected counts for 1 day, got %d", len(counts))
+	}
+	c := counts[0]
+	if c.AgeGroups["18-39"] != 1 || c.AgeGroups["0-4"] != 1 || c.AgeGroups[Unknown] != 1 {
+		t.Errorf("unexpected age groups: %v", c.AgeGroups)
+	}
+	if c.Sex["female"] != 1 || c.Sex["male"] != 1 || c.Sex[Unknown] != 1 {
+		t.Errorf("unexpected sex breakdown: %v", c.Sex)
+	}
+}
+
+func TestFillDemographicCounts(t *testing.T) {
+	from, _ := time.Parse(isoLayout, "2021-08-09")
+	to, _ := time.Parse(isoLayout, "2021-08-11")
+	reported, _ := time.Parse(isoLayout, "2021-08-10")
+	counts := CountCasesByDemographics([]Case{{ReportingDate: &reported, Gender: genderFemale}}, DefaultAgeBands())
+
+	filled := FillDemographicCounts(counts, from, to)
+	if len(filled) != 2 {
+		t.Fatalf("expected counts for 2 days, got %d", len(filled))
+	}
+	if !filled[0].ReportingDate.Equal(from) || len(filled[0].AgeGroups) != 0 || len(filled[0].Sex) != 0 || filled[0].Sex == nil {
+		t.Errorf("expected empty groups on a day without cases, got %+v", filled[0])
+	}
+	if filled[1].Sex["female"] != 1 {
+		t.Errorf("expected the case on the second day, got %+v", filled[1])
+	}
+}
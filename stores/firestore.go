@@ -2,6 +2,7 @@ package stores
 
 import (
 	"context"
+	"covidstats/series"
 	"errors"
 	"fmt"
 	"time"
@@ -33,6 +34,28 @@ func CreateFirestoreDB(ctx context.Context, projectID string) (*Firestore, error
 	}, nil
 }
 
+// maxBatchWrites is the most writes a Firestore batch can hold
+const maxBatchWrites = 500
+
+// setInBatches calls set for each of the n items to be written and commits
+// the writes in batches of at most maxBatchWrites.
+func (db *Firestore) setInBatches(ctx context.Context, n int, set func(batch *fs.WriteBatch, i int)) error {
+	for start := 0; start < n; start += maxBatchWrites {
+		end := start + maxBatchWrites
+		if end > n {
+			end = n
+		}
+		batch := db.Client.Batch()
+		for i := start; i < end; i++ {
+			set(batch, i)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return fmt.Errorf("batch commit error: %w", err)
+		}
+	}
+	return nil
+}
+
 // CasesByDateService is a service for manipulating and querying cases
 type CasesByDateService struct {
 	db         *Firestore
@@ -51,27 +74,26 @@ func NewCasesByDateService(db *Firestore, collection string) *CasesByDateService
 
 // Save persists the cases
 func (c *CasesByDateService) Save(ctx context.Context, cases []CaseCount) error {
-	batch := c.db.Client.Batch()
-
-	for _, cs := range cases {
+	err := c.db.setInBatches(ctx, len(cases), func(batch *fs.WriteBatch, i int) {
+		cs := cases[i]
 		ID := cs.ReportingDate.Format("2006-01-02")
 		ref := c.colRef.Doc(ID)
-		month := cs.ReportingDate.Month()
-		monthStr := fmt.Sprintf("%d", month)
-		if month < 10 {
-			monthStr = fmt.Sprintf("0%d", month)
-		}
-		yr, week := cs.ReportingDate.ISOWeek()
-		wk := fmt.Sprintf("%d-%d", yr, week)
-		batch.Set(ref, map[string]interface{}{
-			"reportingDate": cs.ReportingDate,
-			"count":         cs.Count,
-			"year":          cs.ReportingDate.Year(),
-			"week":          wk,
-			"month":         fmt.Sprintf("%d-%s", cs.ReportingDate.Year(), monthStr),
-		}, fs.MergeAll)
-	}
-	_, err := batch.Commit(ctx)
+		data := periodFields(*cs.ReportingDate)
+		data["reportingDate"] = cs.ReportingDate
+		data["count"] = cs.Count
+		if cs.Districts != nil {
+			// Write every district so a re-sync clears counts that dropped to zero.
+			districts := map[string]interface{}{}
+			for _, d := range Districts() {
+				districts[string(d)] = cs.Districts[d]
+			}
+			data["districts"] = districts
+		}
+		if cs.Classifications != nil {
+			data["classifications"] = cs.Classifications
+		}
+		batch.Set(ref, data, fs.MergeAll)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to save cases: %w", err)
 	}
@@ -79,6 +101,73 @@ func (c *CasesByDateService) Save(ctx context.Context, cases []CaseCount) error
 	return nil
 }
 
+// periodFields returns the fields used to query a daily document by year,
+// month, ISO week and epidemiological week.
+func periodFields(date time.Time) map[string]interface{} {
+	month := date.Month()
+	monthStr := fmt.Sprintf("%d", month)
+	if month < 10 {
+		monthStr = fmt.Sprintf("0%d", month)
+	}
+	return map[string]interface{}{
+		"year":    date.Year(),
+		"week":    isoWeek(date),
+		"epiWeek": series.EpiWeekKey(date),
+		"month":   fmt.Sprintf("%d-%s", date.Year(), monthStr),
+	}
+}
+
+// eachDoc calls fn for every document returned by the query
+func eachDoc(ctx context.Context, q fs.Query, fn func(*fs.DocumentSnapshot) error) error {
+	iter := q.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			return nil
+		}
+		if err != nil {
+			return err //nolint:wrapcheck
+		}
+		if err := fn(doc); err != nil {
+			return err
+		}
+	}
+}
+
+// isoWeek returns the ISO week of the date as yyyy-w
+func isoWeek(date time.Time) string {
+	yr, week := date.ISOWeek()
+	return fmt.Sprintf("%d-%d", yr, week)
+}
+
+// WeekStart returns the Monday starting the ISO week of the date
+func WeekStart(date time.Time) time.Time {
+	return series.WeekStart(date)
+}
+
+// Weeks selects the weeks the weekly statistics are computed for
+type Weeks int
+
+// Week numberings
+const (
+	// ISOWeeks run from Monday to Sunday
+	ISOWeeks Weeks = iota
+	// EpiWeeks are the MMWR epidemiological weeks, from Sunday to Saturday
+	EpiWeeks
+)
+
+// Of returns the week holding the date as yyyy-w, with the year the week
+// belongs to and the day it starts on
+func (wk Weeks) Of(date time.Time) (week string, year int, start time.Time) {
+	if wk == EpiWeeks {
+		year, _ = series.EpiWeek(date)
+		return series.EpiWeekKey(date), year, series.EpiWeekStart(date)
+	}
+	year, _ = date.ISOWeek()
+	return isoWeek(date), year, WeekStart(date)
+}
+
 // CasesCountByDate represents the cases as persisted in Firestore
 type CasesCountByDate struct {
 	ReportingDate *time.Time `json:"reportingDate"`
@@ -86,6 +175,26 @@ type CasesCountByDate struct {
 	Year          int        `json:"year"`
 	Month         string     `json:"month"`
 	Week          string     `json:"week"`
+	// EpiWeek is the MMWR epidemiological week (yyyy-w)
+	EpiWeek   string         `json:"epiWeek"`
+	Districts map[string]int `json:"districts,omitempty"`
+	// Classifications counts the cases by classification name. The
+	// published classifications make up Count.
+	Classifications map[string]int `json:"classifications,omitempty"`
+}
+
+// UnitCounts returns the stored counts of a district, or the national counts
+// when unit is NationalUnit or empty
+func UnitCounts(stored []CasesCountByDate, unit string) []CaseCount {
+	counts := make([]CaseCount, 0, len(stored))
+	for _, c := range stored {
+		count := c.Count
+		if unit != "" && unit != NationalUnit {
+			count = c.Districts[unit]
+		}
+		counts = append(counts, CaseCount{ReportingDate: c.ReportingDate, Count: count})
+	}
+	return counts
 }
 
 // FindByMonth retrieves all cases for a given month
@@ -105,7 +214,7 @@ func (c *CasesByDateService) FindByMonth(ctx context.Context, month string) ([]C
 		var cs CasesCountByDate
 		dataErr := doc.DataTo(&cs)
 		if dataErr != nil {
-			return cases, fmt.Errorf("FindByMonth: unmarshal error: %w", err)
+			return cases, fmt.Errorf("FindByMonth: unmarshal error: %w", dataErr)
 		}
 		cases = append(cases, cs)
 	}
@@ -129,9 +238,70 @@ func (c *CasesByDateService) FindByYear(ctx context.Context, year int) ([]CasesC
 		var cs CasesCountByDate
 		dataErr := doc.DataTo(&cs)
 		if dataErr != nil {
-			return cases, fmt.Errorf("FindByYear: unmarshal error: %w", err)
+			return cases, fmt.Errorf("FindByYear: unmarshal error: %w", dataErr)
+		}
+		cases = append(cases, cs)
+	}
+	return cases, nil
+}
+
+// FindByDateRange retrieves all cases reported from (inclusive) up to
+// (exclusive) the given dates
+func (c *CasesByDateService) FindByDateRange(ctx context.Context, from, to time.Time) ([]CasesCountByDate, error) {
+	var cases []CasesCountByDate
+	iter := c.colRef.Query.
+		Where("reportingDate", ">=", from).
+		Where("reportingDate", "<", to).
+		Documents(ctx)
+
+	for {
+		doc, err := iter.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return cases, fmt.Errorf("FindByDateRange() error: %w", err)
+		}
+
+		var cs CasesCountByDate
+		if dataErr := doc.DataTo(&cs); dataErr != nil {
+			return cases, fmt.Errorf("FindByDateRange: unmarshal error: %w", dataErr)
+		}
+		cases = append(cases, cs)
+	}
+	return cases, nil
+}
+
+// FindByWeek retrieves all cases for a given ISO week (yyyy-w)
+func (c *CasesByDateService) FindByWeek(ctx context.Context, week string) ([]CasesCountByDate, error) {
+	var cases []CasesCountByDate
+	err := eachDoc(ctx, c.colRef.Query.Where("week", "==", week), func(doc *fs.DocumentSnapshot) error {
+		var cs CasesCountByDate
+		if err := doc.DataTo(&cs); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
 		}
 		cases = append(cases, cs)
+		return nil
+	})
+	if err != nil {
+		return cases, fmt.Errorf("FindByWeek() error: %w", err)
+	}
+	return cases, nil
+}
+
+// FindByEpiWeek retrieves all cases for the MMWR epidemiological week
+// starting on the Sunday start. The days are found by reporting date, so
+// days saved before the epiWeek field was added are found too and have it
+// filled in.
+func (c *CasesByDateService) FindByEpiWeek(ctx context.Context, start time.Time) ([]CasesCountByDate, error) {
+	cases, err := c.FindByDateRange(ctx, start, start.AddDate(0, 0, 7))
+	if err != nil {
+		return cases, fmt.Errorf("FindByEpiWeek() error: %w", err)
+	}
+	for i, cs := range cases {
+		if cs.EpiWeek == "" && cs.ReportingDate != nil {
+			cases[i].EpiWeek = series.EpiWeekKey(*cs.ReportingDate)
+		}
 	}
 	return cases, nil
 }
@@ -90,3 +90,52 @@ func TestCasesByDateService_FindByYear(t *testing.T) {
 	}
 	t.Logf("cases: %v", cases)
 }
+
+func TestWeekStart(t *testing.T) {
+	for _, s := range []string{"2021-01-04", "2021-01-06", "2021-01-10"} {
+		d, _ := time.Parse(isoLayout, s)
+		if got := WeekStart(d).Format(isoLayout); got != "2021-01-04" {
+			t.Errorf("WeekStart(%s) = %s, want 2021-01-04", s, got)
+		}
+	}
+}
+
+func TestWeeks_Of(t *testing.T) {
+	tests := []struct {
+		date  string
+		weeks Weeks
+		week  string
+		year  int
+		start string
+	}{
+		{"2021-08-15", ISOWeeks, "2021-32", 2021, "2021-08-09"},
+		{"2021-08-15", EpiWeeks, "2021-33", 2021, "2021-08-15"},
+		{"2021-01-02", ISOWeeks, "2020-53", 2020, "2020-12-28"},
+		{"2021-01-02", EpiWeeks, "2020-53", 2020, "2020-12-27"},
+	}
+	for _, tt := range tests {
+		d, _ := time.Parse(isoLayout, tt.date)
+		week, year, start := tt.weeks.Of(d)
+		if week != tt.week || year != tt.year || start.Format(isoLayout) != tt.start {
+			t.Errorf("Weeks(%d).Of(%s) = %s, %d, %s, want %s, %d, %s", tt.weeks, tt.date, week, year, start.Format(isoLayout), tt.week, tt.year, tt.start)
+		}
+	}
+}
+
+func TestUnitCounts(t *testing.T) {
+	stored := []CasesCountByDate{
+		{ReportingDate: day("2021-08-01"), Count: 5, Districts: map[string]int{string(cy): 2}},
+		{ReportingDate: day("2021-08-02"), Count: 3},
+	}
+	for _, tt := range []struct {
+		unit string
+		want []int
+	}{{"", []int{5, 3}}, {NationalUnit, []int{5, 3}}, {string(cy), []int{2, 0}}} {
+		counts := UnitCounts(stored, tt.unit)
+		for i, want := range tt.want {
+			if counts[i].Count != want || counts[i].ReportingDate != stored[i].ReportingDate {
+				t.Errorf("UnitCounts(%q)[%d] = %+v, want %d", tt.unit, i, counts[i], want)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+)
+
+// ForecastPoint is the forecast of the cases of a day
+type ForecastPoint struct {
+	Date *time.Time `json:"date"`
+	// Horizon is the number of days from the last fitted day
+	Horizon int     `json:"horizon"`
+	Mean    float64 `json:"mean"`
+	// Lower and Upper bound the 95% prediction interval
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Forecast is the forecast made by the sync run of a day
+type Forecast struct {
+	RunDate *time.Time `json:"runDate"`
+	Model   string     `json:"model"`
+	// FittedFrom and FittedTo are the first and last days of the series the
+	// model was fitted to
+	FittedFrom *time.Time      `json:"fittedFrom"`
+	FittedTo   *time.Time      `json:"fittedTo"`
+	Points     []ForecastPoint `json:"points"`
+}
+
+// ForecastService is a service for persisting and querying the forecasts
+type ForecastService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewForecastService creates a new service
+func NewForecastService(db *Firestore, collection string) *ForecastService {
+	return &ForecastService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the forecast, replacing that of an earlier run on the same day
+func (f *ForecastService) Save(ctx context.Context, forecast Forecast) error {
+	points := make([]map[string]interface{}, 0, len(forecast.Points))
+	for _, p := range forecast.Points {
+		points = append(points, map[string]interface{}{
+			"date":    p.Date,
+			"horizon": p.Horizon,
+			"mean":    p.Mean,
+			"lower":   p.Lower,
+			"upper":   p.Upper,
+		})
+	}
+	ref := f.colRef.Doc(forecast.RunDate.Format("2006-01-02"))
+	data := map[string]interface{}{
+		"runDate":    forecast.RunDate,
+		"model":      forecast.Model,
+		"fittedFrom": forecast.FittedFrom,
+		"fittedTo":   forecast.FittedTo,
+		"points":     points,
+	}
+	if _, err := ref.Set(ctx, data); err != nil {
+		return fmt.Errorf("failed to save forecast: %w", err)
+	}
+	return nil
+}
+
+// FindByDateRange retrieves the forecasts of the runs from (inclusive) up to
+// (exclusive) the given dates, oldest first
+func (f *ForecastService) FindByDateRange(ctx context.Context, from, to time.Time) ([]Forecast, error) {
+	var forecasts []Forecast
+	q := f.colRef.Query.Where("runDate", ">=", from).Where("runDate", "<", to).OrderBy("runDate", fs.Asc)
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var fc Forecast
+		if err := doc.DataTo(&fc); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		forecasts = append(forecasts, fc)
+		return nil
+	})
+	if err != nil {
+		return forecasts, fmt.Errorf("ForecastService.FindByDateRange() error: %w", err)
+	}
+	return forecasts, nil
+}
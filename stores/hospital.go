@@ -0,0 +1,195 @@
+package stores
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Go.Data date range types
+const (
+	DateTypeHospitalization = "LNG_REFERENCE_DATA_CATEGORY_PERSON_DATE_TYPE_HOSPITALIZATION"
+	DateTypeICU             = "LNG_REFERENCE_DATA_CATEGORY_PERSON_DATE_TYPE_ICU_ADMISSION"
+)
+
+// Care settings a person can be admitted to
+const (
+	CareHospital = "hospital"
+	CareICU      = "icu"
+)
+
+// DefaultCareTypes maps the Go.Data date range types to the care setting
+// they are counted in
+func DefaultCareTypes() map[string]string {
+	return map[string]string{
+		DateTypeHospitalization: CareHospital,
+		DateTypeICU:             CareICU,
+	}
+}
+
+// ErrInvalidCareType is returned when care types can not be parsed
+var ErrInvalidCareType = errors.New("invalid care type")
+
+// ParseCareTypes parses comma separated type=care pairs, such as
+// "LNG_REFERENCE_DATA_CATEGORY_PERSON_DATE_TYPE_HOSPITALIZATION=hospital"
+func ParseCareTypes(s string) (map[string]string, error) {
+	types := map[string]string{}
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 || kv[0] == "" || (kv[1] != CareHospital && kv[1] != CareICU) {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCareType, pair)
+		}
+		types[kv[0]] = kv[1]
+	}
+	return types, nil
+}
+
+// DateRange is a period recorded on a person, such as a hospitalisation
+type DateRange struct {
+	TypeID    string     `bson:"typeId" json:"typeId"`
+	StartDate *time.Time `bson:"startDate" json:"startDate"`
+	EndDate   *time.Time `bson:"endDate" json:"endDate,omitempty"`
+}
+
+// FindHospitalisedCases finds the cases with any of the classifications and
+// a date range of one of the types that started before to
+func (m *Mongo) FindHospitalisedCases(ctx context.Context, outbreakID string, classifications, types []string, to time.Time) ([]Case, error) {
+	collection := m.Client.Database(m.Database).Collection(m.personCollection())
+	filter := bson.M{
+		"outbreakId":     outbreakID,
+		"classification": bson.M{"$in": classifications},
+		"deleted":        false,
+		"dateRanges": bson.M{"$elemMatch": bson.M{
+			"typeId":    bson.M{"$in": types},
+			"startDate": bson.M{"$lt": to},
+		}},
+	}
+
+	var cases []Case
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return cases, MongoQueryErr{
+			Reason: fmt.Sprintf("failed to retrieve hospitalised cases for outbreak %s", outbreakID),
+			Inner:  err,
+		}
+	}
+	if err := cursor.All(ctx, &cases); err != nil {
+		return cases, MongoQueryErr{
+			Reason: fmt.Sprintf("error executing query for hospitalised cases in outbreak %s", outbreakID),
+			Inner:  err,
+		}
+	}
+
+	return cases, nil
+}
+
+// HospitalCount holds the admissions and occupancy of each care setting on
+// a date
+type HospitalCount struct {
+	Date       *time.Time     `json:"date"`
+	Admissions map[string]int `json:"admissions"`
+	Occupancy  map[string]int `json:"occupancy"`
+}
+
+// CountHospitalisations counts, for every day from (inclusive) up to
+// (exclusive) to, the admissions and the occupancy of each care setting.
+// A stay without an end date lasts until the date of outcome, or is ongoing.
+func CountHospitalisations(cases []Case, careTypes map[string]string, from, to time.Time) []HospitalCount {
+	var counts []HospitalCount
+	index := map[time.Time]int{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		d := day
+		count := HospitalCount{Date: &d, Admissions: map[string]int{}, Occupancy: map[string]int{}}
+		for _, care := range careTypes {
+			count.Admissions[care] = 0
+			count.Occupancy[care] = 0
+		}
+		index[d] = len(counts)
+		counts = append(counts, count)
+	}
+
+	for _, c := range cases {
+		for _, r := range c.DateRanges {
+			care, ok := careTypes[r.TypeID]
+			if !ok || r.StartDate == nil {
+				continue
+			}
+			start := r.StartDate.UTC().Truncate(24 * time.Hour)
+			end := to
+			switch {
+			case r.EndDate != nil:
+				end = r.EndDate.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+			case c.OutcomeDate != nil:
+				end = c.OutcomeDate.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+			}
+			if idx, ok := index[start]; ok {
+				counts[idx].Admissions[care]++
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				if idx, ok := index[day]; ok {
+					counts[idx].Occupancy[care]++
+				}
+			}
+		}
+	}
+	return counts
+}
+
+// HospitalService is a service for persisting and querying the hospital
+// statistics
+type HospitalService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewHospitalService creates a new service
+func NewHospitalService(db *Firestore, collection string) *HospitalService {
+	return &HospitalService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the hospital statistics, replacing those of each date
+func (h *HospitalService) Save(ctx context.Context, counts []HospitalCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	err := h.db.setInBatches(ctx, len(counts), func(batch *fs.WriteBatch, i int) {
+		c := counts[i]
+		ref := h.colRef.Doc(c.Date.Format("2006-01-02"))
+		data := periodFields(*c.Date)
+		data["date"] = c.Date
+		data["admissions"] = c.Admissions
+		data["occupancy"] = c.Occupancy
+		batch.Set(ref, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save hospital stats: %w", err)
+	}
+	return nil
+}
+
+// FindByDateRange retrieves the hospital statistics from (inclusive) up to
+// (exclusive) the given dates
+func (h *HospitalService) FindByDateRange(ctx context.Context, from, to time.Time) ([]HospitalCount, error) {
+	var counts []HospitalCount
+	q := h.colRef.Query.Where("date", ">=", from).Where("date", "<", to).OrderBy("date", fs.Asc)
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var c HospitalCount
+		if err := doc.DataTo(&c); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		counts = append(counts, c)
+		return nil
+	})
+	if err != nil {
+		return counts, fmt.Errorf("HospitalService.FindByDateRange() error: %w", err)
+	}
+	return counts, nil
+}
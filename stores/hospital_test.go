@@ -0,0 +1,34 @@
+package stores
+
+import "testing"
+
+func TestCountHospitalisations(t *testing.T) {
+	cases := []Case{
+		{DateRanges: []DateRange{
+			{TypeID: DateTypeHospitalization, StartDate: day("2021-08-02"), EndDate: day("2021-08-05")},
+			{TypeID: DateTypeICU, StartDate: day("2021-08-03"), EndDate: day("2021-08-04")},
+		}},
+		{OutcomeDate: day("2021-08-03"), DateRanges: []DateRange{
+			{TypeID: DateTypeHospitalization, StartDate: day("2021-07-30")},
+		}},
+		{DateRanges: []DateRange{
+			{TypeID: "LNG_REFERENCE_DATA_CATEGORY_PERSON_DATE_TYPE_ISOLATION", StartDate: day("2021-08-02")},
+		}},
+	}
+
+	counts := CountHospitalisations(cases, DefaultCareTypes(), *day("2021-08-01"), *day("2021-08-07"))
+	if len(counts) != 6 {
+		t.Fatalf("expected 6 days, got %d", len(counts))
+	}
+	wantHospital := []int{1, 2, 2, 1, 1, 0}
+	wantICU := []int{0, 0, 1, 1, 0, 0}
+	for i, c := range counts {
+		if c.Occupancy[CareHospital] != wantHospital[i] || c.Occupancy[CareICU] != wantICU[i] {
+			t.Errorf("%s: occupancy = %v, want hospital %d icu %d",
+				c.Date.Format(isoLayout), c.Occupancy, wantHospital[i], wantICU[i])
+		}
+	}
+	if counts[1].Admissions[CareHospital] != 1 || counts[2].Admissions[CareICU] != 1 {
+		t.Errorf("unexpected admissions: %v %v", counts[1].Admissions, counts[2].Admissions)
+	}
+}
@@ -0,0 +1,174 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// LabResultPositive is the Go.Data positive lab test result
+const LabResultPositive = "LNG_REFERENCE_DATA_CATEGORY_LAB_TEST_RESULT_POSITIVE"
+
+func (m *Mongo) labResultCollection() string {
+	return "labResult"
+}
+
+// LabResult is a lab test as recorded in Go.Data
+type LabResult struct {
+	DateTestPerformed *time.Time `bson:"dateTestPerformed" json:"dateTestPerformed,omitempty"`
+	DateSampleTaken   *time.Time `bson:"dateSampleTaken" json:"dateSampleTaken,omitempty"`
+	Result            string     `bson:"result" json:"result,omitempty"`
+}
+
+// TestDate returns the date the test was performed, or the date the sample
+// was taken when the former was not recorded
+func (l LabResult) TestDate() *time.Time {
+	if l.DateTestPerformed != nil {
+		return l.DateTestPerformed
+	}
+	return l.DateSampleTaken
+}
+
+// FindLabResults finds the lab tests with a result, performed from
+// (inclusive) up to (exclusive) the given dates
+func (m *Mongo) FindLabResults(ctx context.Context, outbreakID string, from, to time.Time) ([]LabResult, error) {
+	collection := m.Client.Database(m.Database).Collection(m.labResultCollection())
+	inRange := bson.M{"$gte": from, "$lt": to}
+	filter := bson.M{
+		"outbreakId": outbreakID,
+		"deleted":    false,
+		"result":     bson.M{"$nin": bson.A{nil, ""}},
+		"$or": bson.A{
+			bson.M{"dateTestPerformed": inRange},
+			bson.M{"dateTestPerformed": nil, "dateSampleTaken": inRange},
+		},
+	}
+
+	var results []LabResult
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return results, MongoQueryErr{
+			Reason: fmt.Sprintf("failed to retrieve lab results for outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return results, MongoQueryErr{
+			Reason: fmt.Sprintf("error executing query for lab results in outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+
+	return results, nil
+}
+
+// TestCount is the number of tests performed, and of those positive, on a
+// date
+type TestCount struct {
+	Date     *time.Time `json:"date"`
+	Tests    int        `json:"tests"`
+	Positive int        `json:"positive"`
+}
+
+// CountTestsByDate counts the tests performed and the positive results on
+// each date
+func CountTestsByDate(results []LabResult) []TestCount {
+	var counts []TestCount
+	byDate := map[time.Time]int{}
+	for _, r := range results {
+		date := r.TestDate()
+		if date == nil {
+			continue
+		}
+		d := date.UTC().Truncate(24 * time.Hour)
+		idx, ok := byDate[d]
+		if !ok {
+			counts = append(counts, TestCount{Date: &d})
+			idx = len(counts) - 1
+			byDate[d] = idx
+		}
+		counts[idx].Tests++
+		if r.Result == LabResultPositive {
+			counts[idx].Positive++
+		}
+	}
+	return counts
+}
+
+// FillTestCounts returns the test counts of every day from (inclusive) up to
+// (exclusive) the given dates, in date order. Days without tests get a zero
+// count, so saving them clears the tests moved to another day.
+func FillTestCounts(counts []TestCount, from, to time.Time) []TestCount {
+	byDate := map[time.Time]TestCount{}
+	for _, c := range counts {
+		if c.Date != nil {
+			byDate[c.Date.UTC().Truncate(24*time.Hour)] = c
+		}
+	}
+	var filled []TestCount
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		c, ok := byDate[day]
+		if !ok {
+			d := day
+			c = TestCount{Date: &d}
+		}
+		filled = append(filled, c)
+	}
+	return filled
+}
+
+// TestsService is a service for persisting and querying the test volumes
+type TestsService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewTestsService creates a new service
+func NewTestsService(db *Firestore, collection string) *TestsService {
+	return &TestsService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the test counts
+func (t *TestsService) Save(ctx context.Context, counts []TestCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	err := t.db.setInBatches(ctx, len(counts), func(batch *fs.WriteBatch, i int) {
+		c := counts[i]
+		ref := t.colRef.Doc(c.Date.Format("2006-01-02"))
+		data := periodFields(*c.Date)
+		data["date"] = c.Date
+		data["tests"] = c.Tests
+		data["positive"] = c.Positive
+		batch.Set(ref, data)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save test counts: %w", err)
+	}
+	return nil
+}
+
+// FindByDateRange retrieves the test counts from (inclusive) up to
+// (exclusive) the given dates
+func (t *TestsService) FindByDateRange(ctx context.Context, from, to time.Time) ([]TestCount, error) {
+	var counts []TestCount
+	q := t.colRef.Query.Where("date", ">=", from).Where("date", "<", to)
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var c TestCount
+		if err := doc.DataTo(&c); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		counts = append(counts, c)
+		return nil
+	})
+	if err != nil {
+		return counts, fmt.Errorf("TestsService.FindByDateRange() error: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,45 @@
+package stores
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountTestsByDate(t *testing.T) {
+	performed, _ := time.Parse(isoLayout, "2021-08-10")
+	sampled, _ := time.Parse(isoLayout, "2021-08-09")
+	results := []LabResult{
+		{DateTestPerformed: &performed, DateSampleTaken: &sampled, Result: LabResultPositive},
+		{DateTestPerformed: &performed, Result: "LNG_REFERENCE_DATA_CATEGORY_LAB_TEST_RESULT_NEGATIVE"},
+		{DateSampleTaken: &sampled, Result: LabResultPositive},
+		{Result: LabResultPositive},
+	}
+
+	counts := CountTestsByDate(results)
+	if len(counts) != 2 {
+		t.Fatalf("expected tests on 2 days, got %d", len(counts))
+	}
+	if !counts[0].Date.Equal(performed) || counts[0].Tests != 2 || counts[0].Positive != 1 {
+		t.Errorf("unexpected count on the test date: %+v", counts[0])
+	}
+	if !counts[1].Date.Equal(sampled) || counts[1].Tests != 1 || counts[1].Positive != 1 {
+		t.Errorf("unexpected count on the sample date: %+v", counts[1])
+	}
+}
+
+func TestFillTestCounts(t *testing.T) {
+	from, _ := time.Parse(isoLayout, "2021-08-09")
+	to, _ := time.Parse(isoLayout, "2021-08-12")
+	performed, _ := time.Parse(isoLayout, "2021-08-11")
+	counts := CountTestsByDate([]LabResult{{DateTestPerformed: &performed, Result: LabResultPositive}})
+
+	filled := FillTestCounts(counts, from, to)
+	if len(filled) != 3 {
+		t.Fatalf("expected counts for 3 days, got %d", len(filled))
+	}
+	for i, want := range []int{0, 0, 1} {
+		if !filled[i].Date.Equal(from.AddDate(0, 0, i)) || filled[i].Tests != want || filled[i].Positive != want {
+			t.Errorf("day %d counted as %+v, want %d tests", i, filled[i], want)
+		}
+	}
+}
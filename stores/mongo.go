@@ -34,6 +34,10 @@ type Mongo struct {
 	Client     *mn.Client
 	Connect    func(context.Context) error
 	Disconnect func(context.Context) error
+	// AddressTypes are the address types, in order of precedence, used to
+	// resolve a residence when the case has no usual place of residence.
+	// Defaults to DefaultAddressTypes().
+	AddressTypes []string
 }
 
 func (m *Mongo) personCollection() string {
@@ -63,6 +67,14 @@ func NewMongoStore(uri, database string) (Mongo, error) {
 	}, nil
 }
 
+// UsualPlaceOfResidence is the Go.Data address type of a person's residence
+const UsualPlaceOfResidence = "LNG_REFERENCE_DATA_CATEGORY_ADDRESS_TYPE_USUAL_PLACE_OF_RESIDENCE"
+
+// DefaultAddressTypes are the address types used to resolve a residence
+func DefaultAddressTypes() []string {
+	return []string{UsualPlaceOfResidence}
+}
+
 // Address represents an address
 type Address struct {
 	TypeID     string `json:"type_id" bson:"typeId"`
@@ -70,12 +82,47 @@ type Address struct {
 	ParentID   string `json:"parent_location_id" bson:"parentLocationId"`
 }
 
+// Age is the age of a person as recorded in Go.Data
+type Age struct {
+	Years  int `bson:"years" json:"years"`
+	Months int `bson:"months" json:"months"`
+}
+
 // Case represents a COVID case
 type Case struct {
-	ReportingDate *time.Time `bson:"dateOfReporting" json:"reportingDate"`
-	ResidenceID   string     `bson:"usualPlaceOfResidenceLocationId"`
-	District      District   `json:"district"`
-	Total         int        `json:"total"`
+	ID             string      `bson:"_id" json:"id,omitempty"`
+	ReportingDate  *time.Time  `bson:"dateOfReporting" json:"reportingDate"`
+	OnsetDate      *time.Time  `bson:"dateOfOnset" json:"onsetDate,omitempty"`
+	ResidenceID    string      `bson:"usualPlaceOfResidenceLocationId"`
+	Addresses      []Address   `bson:"addresses" json:"addresses,omitempty"`
+	Age            *Age        `bson:"age" json:"age,omitempty"`
+	DOB            *time.Time  `bson:"dob" json:"dob,omitempty"`
+	Gender         string      `bson:"gender" json:"gender,omitempty"`
+	Classification string      `bson:"classification" json:"classification,omitempty"`
+	OutcomeID      string      `bson:"outcomeId" json:"outcomeId,omitempty"`
+	OutcomeDate    *time.Time  `bson:"dateOfOutcome" json:"outcomeDate,omitempty"`
+	DateRanges     []DateRange `bson:"dateRanges" json:"dateRanges,omitempty"`
+	WasContact     bool        `bson:"wasContact" json:"wasContact,omitempty"`
+	Vaccines       []Vaccine   `bson:"vaccinesReceived" json:"vaccinesReceived,omitempty"`
+	District       District    `json:"district"`
+	Total          int         `json:"total"`
+}
+
+// ResidenceLocationID returns the location of the case's residence. The usual
+// place of residence is used when set, otherwise the first address matching
+// the address types, in order of precedence.
+func (c Case) ResidenceLocationID(addressTypes []string) string {
+	if c.ResidenceID != "" {
+		return c.ResidenceID
+	}
+	for _, t := range addressTypes {
+		for _, a := range c.Addresses {
+			if a.TypeID == t && a.LocationID != "" {
+				return a.LocationID
+			}
+		}
+	}
+	return ""
 }
 
 // Location represents a location in Belize
@@ -86,19 +133,36 @@ type Location struct {
 
 // FindConfirmedCases finds confirmed cases for a given date range
 func (m *Mongo) FindConfirmedCases(ctx context.Context, outbreakID string, reportingDate time.Time, endDate *time.Time) ([]Case, error) {
+	return m.FindCases(ctx, outbreakID, []string{ClassificationConfirmed}, reportingDate, endDate)
+}
+
+// FindCases finds the cases with any of the classifications for a given date range
+func (m *Mongo) FindCases(ctx context.Context, outbreakID string, classifications []string, reportingDate time.Time, endDate *time.Time) ([]Case, error) {
+	return m.findCasesBy(ctx, "dateOfReporting", outbreakID, classifications, reportingDate, endDate)
+}
+
+// FindCasesByOnset finds the cases with any of the classifications whose
+// symptoms started in a given date range
+func (m *Mongo) FindCasesByOnset(ctx context.Context, outbreakID string, classifications []string, onsetDate time.Time, endDate *time.Time) ([]Case, error) {
+	return m.findCasesBy(ctx, "dateOfOnset", outbreakID, classifications, onsetDate, endDate)
+}
+
+// findCasesBy finds the cases with any of the classifications where the
+// date field falls in the date range
+func (m *Mongo) findCasesBy(ctx context.Context, field, outbreakID string, classifications []string, from time.Time, endDate *time.Time) ([]Case, error) {
 	collection := m.Client.Database(m.Database).Collection(m.personCollection())
 	lastDate := endDate
 	if lastDate == nil {
-		l := reportingDate.Add(time.Hour * 24)
+		l := from.Add(time.Hour * 24)
 		lastDate = &l
 	}
 	filter := bson.M{
 		"outbreakId":     outbreakID,
-		"classification": "LNG_REFERENCE_DATA_CATEGORY_CASE_CLASSIFICATION_CONFIRMED",
+		"classification": bson.M{"$in": classifications},
 		"deleted":        false,
 		"$and": bson.A{
-			bson.M{"dateOfReporting": bson.M{"$gte": reportingDate}},
-			bson.M{"dateOfReporting": bson.M{"$lt": lastDate}},
+			bson.M{field: bson.M{"$gte": from}},
+			bson.M{field: bson.M{"$lt": lastDate}},
 		},
 	}
 
@@ -106,13 +170,13 @@ func (m *Mongo) FindConfirmedCases(ctx context.Context, outbreakID string, repor
 	cursor, err := collection.Find(ctx, filter)
 	if err != nil {
 		return cases, MongoQueryErr{
-			Reason: fmt.Sprintf("failed to retrieve cases for outbreak %s on reporting date %v", outbreakID, reportingDate),
+			Reason: fmt.Sprintf("failed to retrieve cases for outbreak %s on %s %v", outbreakID, field, from),
 			Inner:  err,
 		}
 	}
 	if err := cursor.All(ctx, &cases); err != nil {
 		return cases, MongoQueryErr{
-			Reason: fmt.Sprintf("error executing query for outbreak %s on reporting date %v", outbreakID, reportingDate),
+			Reason: fmt.Sprintf("error executing query for outbreak %s on %s %v", outbreakID, field, from),
 			Inner:  err,
 		}
 	}
@@ -129,8 +193,7 @@ func (m *Mongo) FindLocationByID(ctx context.Context, ID string) (Location, erro
 	if result.Err() != nil {
 		return locs, MongoQueryErr{Reason: "location.FindOne failed", Inner: result.Err()}
 	}
-	var cs Location
-	if err := result.Decode(&cs); err != nil {
+	if err := result.Decode(&locs); err != nil {
 		return locs, MongoQueryErr{
 			Reason: "error decoding location",
 			Inner:  err,
@@ -140,48 +203,74 @@ func (m *Mongo) FindLocationByID(ctx context.Context, ID string) (Location, erro
 	return locs, nil
 }
 
-// AddDistrictToCase adds the district field to the cases
+func (m *Mongo) addressTypes() []string {
+	if len(m.AddressTypes) == 0 {
+		return DefaultAddressTypes()
+	}
+	return m.AddressTypes
+}
+
+// AddDistrictToCase adds the district field to the cases. Cases without a
+// known residence are left without a district.
 func (m *Mongo) AddDistrictToCase(ctx context.Context, cases []Case) ([]Case, error) {
 	var cs []Case
+	// Many cases share a residence, so only look each location up once.
+	districts := map[string]District{"": ""}
 	for _, c := range cases {
-		loc, err := m.FindLocationByID(ctx, c.ResidenceID)
-		if err != nil {
-			return cs, MongoQueryErr{Reason: "error finding location", Inner: err}
+		locationID := c.ResidenceLocationID(m.addressTypes())
+		district, ok := districts[locationID]
+		if !ok {
+			loc, err := m.FindLocationByID(ctx, locationID)
+			if err != nil {
+				return cs, MongoQueryErr{Reason: "error finding location", Inner: err}
+			}
+			district = findDistrictFromCode(loc.ParentLocationID)
+			districts[locationID] = district
 		}
-		c.District = findDistrictFromCode(loc.ParentLocationID)
+		c.District = district
 		cs = append(cs, c)
 	}
 	return cs, nil
 }
 
-//type CasesByDistrict struct {
-//	DateOfReporting *time.Time `json:"dateOfReporting"`
-//	District        District   `json:"district"`
-//	Total           int        `json:"total"`
-//}
-
-//func countCasesByDistrict(cases []Case) []CasesByDistrict {
-//	var cs []CasesByDistrict
-//	for _, c := range cases {
-//		ex := findCaseByDistrictAndDate(cs, c.ReportingDate, c.District)
-//		if ex != nil {
-//			ex.Total = ex.Total + 1
-//		} else {
-//			ex.Total = 1
-//			cs = append(cs, *ex)
-//		}
-//	}
-//	return cs
-//}
-
-//func findCaseByDistrictAndDate(cases []CasesByDistrict, date *time.Time, district District) *CasesByDistrict {
-//	for _, c := range cases {
-//		if c.District == district && c.DateOfReporting == date {
-//			return &c
-//		}
-//	}
-//	return nil
-//}
+// CountCasesByDate counts the cases reported on each date, keeping a
+// per district count alongside the national total.
+func CountCasesByDate(cases []Case) []CaseCount {
+	return countCases(cases, func(c Case) *time.Time { return c.ReportingDate })
+}
+
+// countCases counts the cases on the date returned by dateOf. Cases without
+// a date are skipped.
+func countCases(cases []Case, dateOf func(Case) *time.Time) []CaseCount {
+	var counts []CaseCount
+	byDate := map[time.Time]int{}
+	for _, c := range cases {
+		date := dateOf(c)
+		if date == nil {
+			continue
+		}
+		idx, ok := byDate[*date]
+		if !ok {
+			d := *date
+			counts = append(counts, CaseCount{
+				ReportingDate: &d,
+				Districts:     map[District]int{},
+			})
+			idx = len(counts) - 1
+			byDate[d] = idx
+		}
+		counts[idx].Count++
+		if c.District != "" {
+			counts[idx].Districts[c.District]++
+		}
+	}
+	return counts
+}
+
+// Districts returns all the districts of Belize
+func Districts() []District {
+	return []District{bz, cy, cz, ow, sc, to}
+}
 
 func findDistrictFromCode(code string) District {
 	dist := bz
@@ -227,21 +316,28 @@ type CaseByDistrict struct {
 
 // CaseCount represents how many cases were reported on a date
 type CaseCount struct {
-	ReportingDate *time.Time `bson:"_id" json:"reportingDate"`
-	Count         int        `bson:"count" json:"count"`
+	ReportingDate *time.Time       `bson:"_id" json:"reportingDate"`
+	Count         int              `bson:"count" json:"count"`
+	Districts     map[District]int `bson:"-" json:"districts,omitempty"`
+	// Classifications counts the cases by classification name
+	Classifications map[string]int `bson:"-" json:"classifications,omitempty"`
 }
 
-// GroupCasesByDate retrieves confirmed cases grouped by the reporting date
-func (m *Mongo) GroupCasesByDate(ctx context.Context, outbreakID string, reportingDate time.Time) ([]CaseCount, error) {
+// GroupCasesByDate retrieves cases grouped by the reporting date. Only
+// confirmed cases are counted unless classifications are given.
+func (m *Mongo) GroupCasesByDate(ctx context.Context, outbreakID string, reportingDate time.Time, classifications ...string) ([]CaseCount, error) {
 	var cases []CaseCount
 	collection := m.Client.Database(m.Database).Collection(m.personCollection())
 	lastDate := reportingDate.Add(time.Hour * 24)
+	if len(classifications) == 0 {
+		classifications = []string{ClassificationConfirmed}
+	}
 
 	matchStage := bson.D{
 		{"$match", bson.D{ //nolint:govet
-			{"outbreakId", outbreakID}, //nolint:govet
-			{"classification", "LNG_REFERENCE_DATA_CATEGORY_CASE_CLASSIFICATION_CONFIRMED"}, //nolint:govet
-			{"deleted", false}, //nolint:govet
+			{"outbreakId", outbreakID},                         //nolint:govet
+			{"classification", bson.M{"$in": classifications}}, //nolint:govet
+			{"deleted", false},                                 //nolint:govet
 			{"$and", bson.A{ //nolint:govet
 				bson.M{"dateOfReporting": bson.M{"$gte": reportingDate}},
 				bson.M{"dateOfReporting": bson.M{"$lt": lastDate}},
@@ -9,6 +9,12 @@ import (
 
 const isoLayout string = "2006-01-02"
 
+// day parses an ISO date, for test fixtures
+func day(s string) *time.Time {
+	d, _ := time.Parse(isoLayout, s)
+	return &d
+}
+
 func TestMongo_FindConfirmedCases(t *testing.T) {
 	database := os.Getenv("MONGO_DB")
 	uri := os.Getenv("MONGO_URI")
@@ -100,3 +106,31 @@ func TestMongo_Upload(t *testing.T) {
 		t.Fatalf("saving cases failed: %v", err)
 	}
 }
+
+func TestCase_ResidenceLocationID(t *testing.T) {
+	const other = "LNG_REFERENCE_DATA_CATEGORY_ADDRESS_TYPE_OTHER"
+	addresses := []Address{
+		{TypeID: other, LocationID: "other-1"},
+		{TypeID: UsualPlaceOfResidence, LocationID: ""},
+		{TypeID: UsualPlaceOfResidence, LocationID: "residence-1"},
+		{TypeID: UsualPlaceOfResidence, LocationID: "residence-2"},
+	}
+	tests := []struct {
+		name  string
+		c     Case
+		types []string
+		want  string
+	}{
+		{"first usual residence with a location", Case{Addresses: addresses}, DefaultAddressTypes(), "residence-1"},
+		{"types in order of precedence", Case{Addresses: addresses}, []string{other, UsualPlaceOfResidence}, "other-1"},
+		{"falls back to the next type", Case{Addresses: addresses[1:]}, []string{other, UsualPlaceOfResidence}, "residence-1"},
+		{"usual place of residence first", Case{ResidenceID: "residence", Addresses: addresses}, []string{other}, "residence"},
+		{"no matching address", Case{Addresses: addresses[:1]}, DefaultAddressTypes(), ""},
+		{"no addresses", Case{}, DefaultAddressTypes(), ""},
+	}
+	for _, tt := range tests {
+		if got := tt.c.ResidenceLocationID(tt.types); got != tt.want {
+			t.Errorf("%s: ResidenceLocationID() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Go.Data outcome reference data
+const (
+	OutcomeDeceased  = "LNG_REFERENCE_DATA_CATEGORY_OUTCOME_DECEASED"
+	OutcomeRecovered = "LNG_REFERENCE_DATA_CATEGORY_OUTCOME_RECOVERED"
+)
+
+// FindDeceasedCases finds the cases with any of the classifications whose
+// outcome is death, with a date of outcome from (inclusive) up to (exclusive)
+// the given dates.
+func (m *Mongo) FindDeceasedCases(ctx context.Context, outbreakID string, classifications []string, from, to time.Time) ([]Case, error) {
+	collection := m.Client.Database(m.Database).Collection(m.personCollection())
+	filter := bson.M{
+		"outbreakId":     outbreakID,
+		"classification": bson.M{"$in": classifications},
+		"outcomeId":      OutcomeDeceased,
+		"deleted":        false,
+		"$and": bson.A{
+			bson.M{"dateOfOutcome": bson.M{"$gte": from}},
+			bson.M{"dateOfOutcome": bson.M{"$lt": to}},
+		},
+	}
+
+	var cases []Case
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return cases, MongoQueryErr{
+			Reason: fmt.Sprintf("failed to retrieve deaths for outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+	if err := cursor.All(ctx, &cases); err != nil {
+		return cases, MongoQueryErr{
+			Reason: fmt.Sprintf("error executing query for deaths in outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+
+	return cases, nil
+}
+
+// CountDeathsByDate counts the deceased cases on each date of outcome,
+// keeping a per district count alongside the national total. The count's
+// ReportingDate holds the date of outcome.
+func CountDeathsByDate(cases []Case) []CaseCount {
+	return countCases(cases, func(c Case) *time.Time {
+		if c.OutcomeID != OutcomeDeceased {
+			return nil
+		}
+		return c.OutcomeDate
+	})
+}
+
+// RecoveryDate returns the date the case recovered. Cases with an explicit
+// recovered outcome use the date of outcome, or the reporting date when the
+// outcome has no date. Cases without an outcome are
+// considered recovered once the isolation period after the reporting date
+// has passed. Deceased cases never recover.
+func (c Case) RecoveryDate(isolationDays int) *time.Time {
+	switch c.OutcomeID {
+	case OutcomeRecovered:
+		if c.OutcomeDate == nil {
+			return c.ReportingDate
+		}
+		return c.OutcomeDate
+	case OutcomeDeceased:
+		return nil
+	}
+	if c.ReportingDate == nil {
+		return nil
+	}
+	d := c.ReportingDate.AddDate(0, 0, isolationDays)
+	return &d
+}
+
+// FindRecoveredCases finds the cases with any of the classifications that
+// recovered from (inclusive) up to (exclusive) the given dates. Cases without
+// an outcome are included when their isolation period ended in the date
+// range, and recovered cases without a date of outcome when they were
+// reported in it.
+func (m *Mongo) FindRecoveredCases(ctx context.Context, outbreakID string, classifications []string, from, to time.Time, isolationDays int) ([]Case, error) {
+	collection := m.Client.Database(m.Database).Collection(m.personCollection())
+	filter := bson.M{
+		"outbreakId":     outbreakID,
+		"classification": bson.M{"$in": classifications},
+		"deleted":        false,
+		"$or": bson.A{
+			bson.M{
+				"outcomeId": OutcomeRecovered,
+				"$and": bson.A{
+					bson.M{"dateOfOutcome": bson.M{"$gte": from}},
+					bson.M{"dateOfOutcome": bson.M{"$lt": to}},
+				},
+			},
+			bson.M{
+				"outcomeId":     OutcomeRecovered,
+				"dateOfOutcome": nil,
+				"$and": bson.A{
+					bson.M{"dateOfReporting": bson.M{"$gte": from}},
+					bson.M{"dateOfReporting": bson.M{"$lt": to}},
+				},
+			},
+			bson.M{
+				"outcomeId": bson.M{"$nin": bson.A{OutcomeRecovered, OutcomeDeceased}},
+				"$and": bson.A{
+					bson.M{"dateOfReporting": bson.M{"$gte": from.AddDate(0, 0, -isolationDays)}},
+					bson.M{"dateOfReporting": bson.M{"$lt": to.AddDate(0, 0, -isolationDays)}},
+				},
+			},
+		},
+	}
+
+	var cases []Case
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return cases, MongoQueryErr{
+			Reason: fmt.Sprintf("failed to retrieve recoveries for outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+	if err := cursor.All(ctx, &cases); err != nil {
+		return cases, MongoQueryErr{
+			Reason: fmt.Sprintf("error executing query for recoveries in outbreak %s from %v to %v", outbreakID, from, to),
+			Inner:  err,
+		}
+	}
+
+	return cases, nil
+}
+
+// CountRecoveriesByDate counts the recovered cases on each date of recovery,
+// keeping a per district count alongside the national total. The count's
+// ReportingDate holds the date of recovery.
+func CountRecoveriesByDate(cases []Case, isolationDays int) []CaseCount {
+	return countCases(cases, func(c Case) *time.Time { return c.RecoveryDate(isolationDays) })
+}
@@ -0,0 +1,77 @@
+package stores
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountDeathsByDate(t *testing.T) {
+	reported, _ := time.Parse(isoLayout, "2021-08-01")
+	died, _ := time.Parse(isoLayout, "2021-08-12")
+	cases := []Case{
+		{ReportingDate: &reported, OutcomeID: OutcomeDeceased, OutcomeDate: &died, District: cy},
+		{ReportingDate: &reported, OutcomeID: OutcomeDeceased, OutcomeDate: &died, District: to},
+		{ReportingDate: &reported, OutcomeID: OutcomeDeceased},
+		{ReportingDate: &reported, OutcomeDate: &died},
+	}
+
+	counts := CountDeathsByDate(cases)
+	if len(counts) != 1 {
+		t.Fatalf("expected deaths on 1 day, got %d", len(counts))
+	}
+	if !counts[0].ReportingDate.Equal(died) {
+		t.Errorf("deaths counted on %v, want the date of outcome %v", counts[0].ReportingDate, died)
+	}
+	if counts[0].Count != 2 || counts[0].Districts[cy] != 1 || counts[0].Districts[to] != 1 {
+		t.Errorf("unexpected deaths count: %+v", counts[0])
+	}
+}
+
+func TestCountRecoveriesByDate(t *testing.T) {
+	reported, _ := time.Parse(isoLayout, "2021-08-01")
+	recovered, _ := time.Parse(isoLayout, "2021-08-10")
+	cases := []Case{
+		{ReportingDate: &reported, OutcomeID: OutcomeRecovered, OutcomeDate: &recovered},
+		{ReportingDate: &reported},
+		{ReportingDate: &reported, OutcomeID: OutcomeDeceased, OutcomeDate: &recovered},
+		{ReportingDate: &reported, OutcomeID: OutcomeRecovered},
+	}
+
+	counts := CountRecoveriesByDate(cases, 14)
+	if len(counts) != 3 {
+		t.Fatalf("expected recoveries on 3 days, got %d", len(counts))
+	}
+	if !counts[0].ReportingDate.Equal(recovered) || counts[0].Count != 1 {
+		t.Errorf("explicit recovery counted as %+v", counts[0])
+	}
+	if want := reported.AddDate(0, 0, 14); !counts[1].ReportingDate.Equal(want) || counts[1].Count != 1 {
+		t.Errorf("derived recovery counted as %+v, want on %v", counts[1], want)
+	}
+	if !counts[2].ReportingDate.Equal(reported) || counts[2].Count != 1 {
+		t.Errorf("recovery without a date of outcome counted as %+v, want on %v", counts[2], reported)
+	}
+}
+
+func TestCountDeathsByDate_zeroDays(t *testing.T) {
+	from, _ := time.Parse(isoLayout, "2021-08-10")
+	to, _ := time.Parse(isoLayout, "2021-08-13")
+	// The death first recorded on the 11th was moved to the 12th, at noon.
+	died, _ := time.Parse(time.RFC3339, "2021-08-12T12:00:00Z")
+	cases := []Case{{OutcomeID: OutcomeDeceased, OutcomeDate: &died, District: cy}}
+
+	counts := FillCaseCounts(CountDeathsByDate(cases), nil, from, to)
+	if len(counts) != 3 {
+		t.Fatalf("expected counts for 3 days, got %d", len(counts))
+	}
+	for i, want := range []int{0, 0, 1} {
+		if !counts[i].ReportingDate.Equal(from.AddDate(0, 0, i)) || counts[i].Count != want {
+			t.Errorf("day %d counted as %+v, want %d deaths", i, counts[i], want)
+		}
+	}
+	if counts[1].Districts == nil {
+		t.Error("expected the districts of the 11th to be cleared")
+	}
+	if counts[1].Classifications != nil {
+		t.Errorf("expected no classifications on a deaths count, got %v", counts[1].Classifications)
+	}
+}
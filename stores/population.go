@@ -0,0 +1,148 @@
+package stores
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// NationalUnit is the admin unit used for the country wide population
+const NationalUnit = "National"
+
+// ErrNoPopulation is returned when no population is known for an admin unit
+var ErrNoPopulation = errors.New("no population for admin unit")
+
+// ErrInvalidPopulation is returned when the population CSV is malformed
+var ErrInvalidPopulation = errors.New("invalid population csv")
+
+// Population holds the population denominators per admin unit and year
+type Population struct {
+	// Version identifies the dataset the denominators were loaded from
+	Version string
+	units   map[string]map[int]int
+}
+
+// OpenPopulation loads the population denominators from a CSV file.
+// The file name, without its extension, is used as the dataset version.
+func OpenPopulation(path string) (*Population, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("OpenPopulation: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	version := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return LoadPopulation(f, version)
+}
+
+// LoadPopulation reads population denominators from CSV. The first row is a
+// header that must contain the admin_unit, year and population columns, in
+// any order and alongside other columns, such as:
+//
+//	admin_unit,year,population
+//	National,2021,400000
+//	Cayo,2021,100000
+//
+// Admin units are the district names, or NationalUnit for the whole country.
+// Rows may have fewer columns than the header as long as they hold the three
+// columns read.
+func LoadPopulation(r io.Reader, version string) (*Population, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("LoadPopulation: failed to read csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("LoadPopulation: %w: empty file", ErrInvalidPopulation)
+	}
+
+	cols := map[string]int{}
+	for i, h := range records[0] {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, h := range []string{"admin_unit", "year", "population"} {
+		if _, ok := cols[h]; !ok {
+			return nil, fmt.Errorf("LoadPopulation: %w: missing column %q", ErrInvalidPopulation, h)
+		}
+	}
+
+	needed := 0
+	for _, h := range []string{"admin_unit", "year", "population"} {
+		if cols[h] >= needed {
+			needed = cols[h] + 1
+		}
+	}
+
+	p := &Population{Version: version, units: map[string]map[int]int{}}
+	for i, rec := range records[1:] {
+		if len(rec) < needed {
+			return nil, fmt.Errorf("LoadPopulation: %w: line %d: expected at least %d columns, got %d", ErrInvalidPopulation, i+2, needed, len(rec))
+		}
+		unit := strings.TrimSpace(rec[cols["admin_unit"]])
+		year, yrErr := strconv.Atoi(strings.TrimSpace(rec[cols["year"]]))
+		if yrErr != nil {
+			return nil, fmt.Errorf("LoadPopulation: line %d: invalid year: %w", i+2, yrErr)
+		}
+		pop, popErr := strconv.Atoi(strings.TrimSpace(rec[cols["population"]]))
+		if popErr != nil {
+			return nil, fmt.Errorf("LoadPopulation: line %d: invalid population: %w", i+2, popErr)
+		}
+		if pop <= 0 {
+			return nil, fmt.Errorf("LoadPopulation: %w: line %d: population must be positive", ErrInvalidPopulation, i+2)
+		}
+		if p.units[unit] == nil {
+			p.units[unit] = map[int]int{}
+		}
+		p.units[unit][year] = pop
+	}
+	return p, nil
+}
+
+// For returns the population of an admin unit in a year. When the year is not
+// in the dataset the closest earlier year is used, or failing that the
+// closest later year.
+func (p *Population) For(unit string, year int) (int, error) {
+	years, ok := p.units[unit]
+	if !ok || len(years) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNoPopulation, unit)
+	}
+	if pop, ok := years[year]; ok {
+		return pop, nil
+	}
+	earlier, later := 0, 0
+	for y := range years {
+		if y < year && y > earlier {
+			earlier = y
+		}
+		if y > year && (later == 0 || y < later) {
+			later = y
+		}
+	}
+	if earlier != 0 {
+		return years[earlier], nil
+	}
+	return years[later], nil
+}
+
+// National returns the country wide population for a year. When the dataset
+// has no national figures the district populations are summed.
+func (p *Population) National(year int) (int, error) {
+	if pop, err := p.For(NationalUnit, year); err == nil {
+		return pop, nil
+	}
+	total := 0
+	for _, d := range Districts() {
+		pop, err := p.For(string(d), year)
+		if err != nil {
+			return 0, err
+		}
+		total += pop
+	}
+	return total, nil
+}
@@ -0,0 +1,71 @@
+package stores
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const populationCSV = `admin_unit,year,population
+Belize,2020,100
+Belize,2022,120
+Cayo,2020,80
+Corozal,2020,40
+Orange Walk,2020,50
+Stann Creek,2020,30
+Toledo,2020,20
+`
+
+func TestLoadPopulation(t *testing.T) {
+	pop, err := LoadPopulation(strings.NewReader(populationCSV), "test")
+	if err != nil {
+		t.Fatalf("LoadPopulation failed: %v", err)
+	}
+
+	tests := []struct {
+		unit string
+		year int
+		want int
+	}{
+		{"Belize", 2020, 100},
+		{"Belize", 2021, 100},
+		{"Belize", 2023, 120},
+		{"Belize", 2019, 100},
+		{"Toledo", 2021, 20},
+	}
+	for _, tt := range tests {
+		got, err := pop.For(tt.unit, tt.year) //nolint:govet
+		if err != nil {
+			t.Fatalf("For(%s, %d) failed: %v", tt.unit, tt.year, err)
+		}
+		if got != tt.want {
+			t.Errorf("For(%s, %d) = %d, want %d", tt.unit, tt.year, got, tt.want)
+		}
+	}
+
+	national, err := pop.National(2020)
+	if err != nil {
+		t.Fatalf("National failed: %v", err)
+	}
+	if national != 320 {
+		t.Errorf("National(2020) = %d, want 320", national)
+	}
+
+	if _, err := pop.For("Nowhere", 2020); !errors.Is(err, ErrNoPopulation) {
+		t.Errorf("For unknown unit returned %v, want ErrNoPopulation", err)
+	}
+}
+
+func TestLoadPopulation_MissingColumn(t *testing.T) {
+	_, err := LoadPopulation(strings.NewReader("admin_unit,population\nBelize,100\n"), "test")
+	if !errors.Is(err, ErrInvalidPopulation) {
+		t.Errorf("LoadPopulation returned %v, want ErrInvalidPopulation", err)
+	}
+}
+
+func TestLoadPopulation_ShortRow(t *testing.T) {
+	_, err := LoadPopulation(strings.NewReader("admin_unit,year,population,notes\nBelize,2020,100\nCayo,2020\n"), "test")
+	if !errors.Is(err, ErrInvalidPopulation) {
+		t.Errorf("LoadPopulation returned %v, want ErrInvalidPopulation", err)
+	}
+}
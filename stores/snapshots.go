@@ -0,0 +1,84 @@
+package stores
+
+import (
+	"context"
+	"covidstats/series"
+	"fmt"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+)
+
+// Snapshot is the published case count of each reporting date as seen by the
+// sync run of a day. Comparing the snapshots of successive runs shows how the
+// counts of a reporting date grow as cases are backfilled.
+type Snapshot struct {
+	RunDate *time.Time `json:"runDate"`
+	// Counts holds every reporting date the run synced, including those
+	// without cases
+	Counts series.Daily `json:"counts"`
+}
+
+// NewSnapshot returns the snapshot of the counts synced on runDate for the
+// reporting dates from (inclusive) up to (exclusive) to. Dates after the run
+// are left out.
+func NewSnapshot(runDate time.Time, counts []CaseCount, from, to time.Time) Snapshot {
+	run := runDate.UTC().Truncate(24 * time.Hour)
+	daily := series.Daily{}
+	for day := from; day.Before(to) && !day.After(run); day = day.AddDate(0, 0, 1) {
+		daily.Add(day, 0)
+	}
+	for _, c := range counts {
+		if c.ReportingDate != nil && !c.ReportingDate.After(run) {
+			daily.Add(*c.ReportingDate, c.Count)
+		}
+	}
+	return Snapshot{RunDate: &run, Counts: daily}
+}
+
+// SnapshotService is a service for persisting and querying the snapshots of
+// the sync runs
+type SnapshotService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewSnapshotService creates a new service
+func NewSnapshotService(db *Firestore, collection string) *SnapshotService {
+	return &SnapshotService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the snapshot, replacing that of an earlier run on the same day
+func (s *SnapshotService) Save(ctx context.Context, snapshot Snapshot) error {
+	ref := s.colRef.Doc(snapshot.RunDate.Format("2006-01-02"))
+	data := map[string]interface{}{
+		"runDate": snapshot.RunDate,
+		"counts":  map[string]int(snapshot.Counts),
+	}
+	if _, err := ref.Set(ctx, data); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// FindSince retrieves the snapshots of the runs from (inclusive) the given
+// date, oldest first
+func (s *SnapshotService) FindSince(ctx context.Context, from time.Time) ([]Snapshot, error) {
+	var snapshots []Snapshot
+	q := s.colRef.Query.Where("runDate", ">=", from).OrderBy("runDate", fs.Asc)
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var snap Snapshot
+		if err := doc.DataTo(&snap); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		snapshots = append(snapshots, snap)
+		return nil
+	})
+	if err != nil {
+		return snapshots, fmt.Errorf("SnapshotService.FindSince() error: %w", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,27 @@
+package stores
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	counts := []CaseCount{
+		{ReportingDate: day("2021-12-30"), Count: 4},
+		{ReportingDate: day("2022-01-02"), Count: 7},
+	}
+	snap := NewSnapshot(day("2022-01-02").Add(15*time.Hour), counts, *day("2021-12-29"), *day("2022-01-05"))
+
+	if snap.RunDate.Format(isoLayout) != "2022-01-02" {
+		t.Errorf("expected run date 2022-01-02, got %s", snap.RunDate)
+	}
+	if len(snap.Counts) != 5 {
+		t.Errorf("expected the 5 days up to the run, got %v", snap.Counts)
+	}
+	if snap.Counts["2021-12-30"] != 4 || snap.Counts["2022-01-02"] != 7 {
+		t.Errorf("unexpected counts %v", snap.Counts)
+	}
+	if v, ok := snap.Counts["2021-12-31"]; !ok || v != 0 {
+		t.Errorf("expected 2021-12-31 to be zero filled, got %v", snap.Counts)
+	}
+}
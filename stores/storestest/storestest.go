@@ -0,0 +1,24 @@
+// Package storestest provides the case series fixtures of the tests of the
+// packages that model the stored counts.
+package storestest
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"time"
+)
+
+// Counts returns the counts of consecutive days from start, a yyyy-mm-dd
+// date, with one day per value
+func Counts(start string, values ...int) []stores.CaseCount {
+	first, err := time.Parse(series.Layout, start)
+	if err != nil {
+		panic(err)
+	}
+	cc := make([]stores.CaseCount, 0, len(values))
+	for i, v := range values {
+		d := first.AddDate(0, 0, i)
+		cc = append(cc, stores.CaseCount{ReportingDate: &d, Count: v})
+	}
+	return cc
+}
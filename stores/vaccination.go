@@ -0,0 +1,211 @@
+package stores
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	fs "cloud.google.com/go/firestore"
+)
+
+// Vaccination statuses
+const (
+	Unvaccinated        = "unvaccinated"
+	PartiallyVaccinated = "partiallyVaccinated"
+	FullyVaccinated     = "fullyVaccinated"
+	Boosted             = "boosted"
+)
+
+// vaccineNotVaccinated is the Go.Data status of a vaccine that was not given
+const vaccineNotVaccinated = "LNG_REFERENCE_DATA_CATEGORY_VACCINE_STATUS_NOT_VACCINATED"
+
+// Vaccine is a vaccine dose received by a person as recorded in Go.Data
+type Vaccine struct {
+	Vaccine string     `bson:"vaccine" json:"vaccine"`
+	Date    *time.Time `bson:"date" json:"date,omitempty"`
+	Status  string     `bson:"status" json:"status,omitempty"`
+}
+
+// VaccinationRule decides the vaccination status of a person
+type VaccinationRule struct {
+	// WaitingDays is how long after a dose it is considered effective
+	WaitingDays int
+	// PrimaryDoses is the number of doses in the primary series
+	PrimaryDoses int
+	// SingleDoseVaccines are the vaccines whose primary series is one dose
+	SingleDoseVaccines []string
+}
+
+// DefaultVaccinationRule is a two dose primary series effective 14 days
+// after each dose, except for the Janssen vaccine
+func DefaultVaccinationRule() VaccinationRule {
+	return VaccinationRule{
+		WaitingDays:        14,
+		PrimaryDoses:       2,
+		SingleDoseVaccines: []string{"LNG_REFERENCE_DATA_CATEGORY_VACCINE_JANSSEN"},
+	}
+}
+
+// VaccinationStatus returns the vaccination status of the case on the given
+// date. Only the doses given at least WaitingDays before the date count.
+func (c Case) VaccinationStatus(rule VaccinationRule, date time.Time) string {
+	effective := 0
+	primary := rule.PrimaryDoses
+	for _, v := range c.Vaccines {
+		if v.Date == nil || v.Status == vaccineNotVaccinated {
+			continue
+		}
+		if v.Date.AddDate(0, 0, rule.WaitingDays).After(date) {
+			continue
+		}
+		effective++
+		for _, single := range rule.SingleDoseVaccines {
+			if v.Vaccine == single {
+				primary = 1
+			}
+		}
+	}
+	switch {
+	case effective == 0:
+		return Unvaccinated
+	case effective < primary:
+		return PartiallyVaccinated
+	case effective == primary:
+		return FullyVaccinated
+	default:
+		return Boosted
+	}
+}
+
+// VaccinationStatusCount is the breakdown by vaccination status of the cases
+// and deaths reported in a week
+type VaccinationStatusCount struct {
+	Week   string         `json:"week"`
+	Year   int            `json:"year"`
+	Cases  map[string]int `json:"cases"`
+	Deaths map[string]int `json:"deaths"`
+}
+
+// CountVaccinationStatusByWeek counts, for each of the weeks, the cases by
+// their vaccination status on the reporting date. Deaths are counted in the
+// week of the date of outcome.
+func CountVaccinationStatusByWeek(cases, deaths []Case, rule VaccinationRule, weeks Weeks) []VaccinationStatusCount {
+	var counts []VaccinationStatusCount
+	byWeek := map[string]int{}
+	entry := func(date time.Time) *VaccinationStatusCount {
+		week, yr, _ := weeks.Of(date)
+		idx, ok := byWeek[week]
+		if !ok {
+			c := VaccinationStatusCount{Week: week, Year: yr, Cases: map[string]int{}, Deaths: map[string]int{}}
+			for _, s := range []string{Unvaccinated, PartiallyVaccinated, FullyVaccinated, Boosted} {
+				c.Cases[s] = 0
+				c.Deaths[s] = 0
+			}
+			counts = append(counts, c)
+			idx = len(counts) - 1
+			byWeek[week] = idx
+		}
+		return &counts[idx]
+	}
+
+	for _, c := range cases {
+		if c.ReportingDate == nil {
+			continue
+		}
+		entry(*c.ReportingDate).Cases[c.VaccinationStatus(rule, *c.ReportingDate)]++
+	}
+	for _, d := range deaths {
+		if d.OutcomeDate == nil || d.ReportingDate == nil {
+			continue
+		}
+		entry(*d.OutcomeDate).Deaths[d.VaccinationStatus(rule, *d.ReportingDate)]++
+	}
+	return counts
+}
+
+// FillVaccinationStatusCounts returns the counts of every week starting from
+// (inclusive) up to (exclusive) the given dates, in week order. Weeks without
+// cases or deaths get zero counts, so saving them clears the counts of a week
+// left without cases.
+func FillVaccinationStatusCounts(counts []VaccinationStatusCount, weeks Weeks, from, to time.Time) []VaccinationStatusCount {
+	byWeek := map[string]VaccinationStatusCount{}
+	for _, c := range counts {
+		byWeek[c.Week] = c
+	}
+	var filled []VaccinationStatusCount
+	_, _, start := weeks.Of(from)
+	for day := start; day.Before(to); day = day.AddDate(0, 0, 7) {
+		week, yr, _ := weeks.Of(day)
+		c, ok := byWeek[week]
+		if !ok {
+			c = VaccinationStatusCount{Week: week, Year: yr, Cases: map[string]int{}, Deaths: map[string]int{}}
+			for _, s := range []string{Unvaccinated, PartiallyVaccinated, FullyVaccinated, Boosted} {
+				c.Cases[s] = 0
+				c.Deaths[s] = 0
+			}
+		}
+		filled = append(filled, c)
+	}
+	return filled
+}
+
+// VaccinationStatusService is a service for persisting and querying the
+// vaccination status of cases
+type VaccinationStatusService struct {
+	db     *Firestore
+	colRef *fs.CollectionRef
+}
+
+// NewVaccinationStatusService creates a new service
+func NewVaccinationStatusService(db *Firestore, collection string) *VaccinationStatusService {
+	return &VaccinationStatusService{
+		db:     db,
+		colRef: db.Client.Collection(collection),
+	}
+}
+
+// Save persists the weekly counts, replacing those of each week
+func (v *VaccinationStatusService) Save(ctx context.Context, counts []VaccinationStatusCount) error {
+	if len(counts) == 0 {
+		return nil
+	}
+	err := v.db.setInBatches(ctx, len(counts), func(batch *fs.WriteBatch, i int) {
+		c := counts[i]
+		batch.Set(v.colRef.Doc(c.Week), map[string]interface{}{
+			"week":   c.Week,
+			"year":   c.Year,
+			"cases":  c.Cases,
+			"deaths": c.Deaths,
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save vaccination status: %w", err)
+	}
+	return nil
+}
+
+// FindByYear retrieves the weekly counts of the weeks in a year
+func (v *VaccinationStatusService) FindByYear(ctx context.Context, year int) ([]VaccinationStatusCount, error) {
+	return v.find(ctx, v.colRef.Query.Where("year", "==", year))
+}
+
+// FindByWeek retrieves the counts of a week (yyyy-w)
+func (v *VaccinationStatusService) FindByWeek(ctx context.Context, week string) ([]VaccinationStatusCount, error) {
+	return v.find(ctx, v.colRef.Query.Where("week", "==", week))
+}
+
+func (v *VaccinationStatusService) find(ctx context.Context, q fs.Query) ([]VaccinationStatusCount, error) {
+	var counts []VaccinationStatusCount
+	err := eachDoc(ctx, q, func(doc *fs.DocumentSnapshot) error {
+		var c VaccinationStatusCount
+		if err := doc.DataTo(&c); err != nil {
+			return fmt.Errorf("unmarshal error: %w", err)
+		}
+		counts = append(counts, c)
+		return nil
+	})
+	if err != nil {
+		return counts, fmt.Errorf("VaccinationStatusService.find() error: %w", err)
+	}
+	return counts, nil
+}
@@ -0,0 +1,66 @@
+package stores
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCase_VaccinationStatus(t *testing.T) {
+	pfizer := "LNG_REFERENCE_DATA_CATEGORY_VACCINE_PFIZER"
+	rule := DefaultVaccinationRule()
+	reported := *day("2021-09-30")
+
+	tests := []struct {
+		name     string
+		vaccines []Vaccine
+		want     string
+	}{
+		{"no doses", nil, Unvaccinated},
+		{"dose within waiting period", []Vaccine{{Vaccine: pfizer, Date: day("2021-09-20")}}, Unvaccinated},
+		{"one of two doses", []Vaccine{{Vaccine: pfizer, Date: day("2021-08-01")}}, PartiallyVaccinated},
+		{"second dose within waiting period", []Vaccine{
+			{Vaccine: pfizer, Date: day("2021-08-01")},
+			{Vaccine: pfizer, Date: day("2021-09-20")},
+		}, PartiallyVaccinated},
+		{"two doses", []Vaccine{
+			{Vaccine: pfizer, Date: day("2021-07-01")},
+			{Vaccine: pfizer, Date: day("2021-08-01")},
+		}, FullyVaccinated},
+		{"single dose vaccine", []Vaccine{{Vaccine: rule.SingleDoseVaccines[0], Date: day("2021-08-01")}}, FullyVaccinated},
+		{"booster", []Vaccine{
+			{Vaccine: pfizer, Date: day("2021-03-01")},
+			{Vaccine: pfizer, Date: day("2021-04-01")},
+			{Vaccine: pfizer, Date: day("2021-09-01")},
+		}, Boosted},
+		{"not vaccinated status", []Vaccine{{Vaccine: pfizer, Date: day("2021-08-01"), Status: vaccineNotVaccinated}}, Unvaccinated},
+	}
+	for _, tt := range tests {
+		c := Case{ReportingDate: &reported, Vaccines: tt.vaccines}
+		if got := c.VaccinationStatus(rule, reported); got != tt.want {
+			t.Errorf("%s: status = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFillVaccinationStatusCounts(t *testing.T) {
+	from, _ := time.Parse(isoLayout, "2021-08-02")
+	to, _ := time.Parse(isoLayout, "2021-08-23")
+	reported, _ := time.Parse(isoLayout, "2021-08-10")
+	counts := CountVaccinationStatusByWeek([]Case{{ReportingDate: &reported}}, nil, VaccinationRule{}, ISOWeeks)
+
+	filled := FillVaccinationStatusCounts(counts, ISOWeeks, from, to)
+	if len(filled) != 3 {
+		t.Fatalf("expected counts for 3 weeks, got %d", len(filled))
+	}
+	for i, want := range []struct {
+		week  string
+		cases int
+	}{{"2021-31", 0}, {"2021-32", 1}, {"2021-33", 0}} {
+		if filled[i].Week != want.week || filled[i].Cases[Unvaccinated] != want.cases {
+			t.Errorf("week %d counted as %+v, want %d cases in %s", i, filled[i], want.cases, want.week)
+		}
+	}
+	if n, ok := filled[0].Deaths[Unvaccinated]; !ok || n != 0 {
+		t.Errorf("expected zero deaths on an empty week, got %v", filled[0].Deaths)
+	}
+}
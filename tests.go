@@ -0,0 +1,78 @@
+package covidstats
+
+import (
+	"covidstats/series"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// testStats are the tests performed on a day and the share that were
+// positive. Positivity is left out when no tests were performed.
+type testStats struct {
+	Date               string   `json:"date"`
+	Tests              int      `json:"tests"`
+	Positive           int      `json:"positive"`
+	Positivity         *float64 `json:"positivity"`
+	SevenDayTests      int      `json:"sevenDayTests"`
+	SevenDayPositive   int      `json:"sevenDayPositive"`
+	SevenDayPositivity *float64 `json:"sevenDayPositivity"`
+}
+
+// HandleTestStats is the handler that returns the daily test volume and
+// positivity, along with the 7 day positivity, for the requested date range.
+func (s *Server) HandleTestStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleTestStats")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	from, to, rangeErr := parseRange(r, 90)
+	if rangeErr != nil {
+		http.Error(w, rangeErr.Error(), http.StatusBadRequest)
+		return
+	}
+
+	counts, findErr := s.tests.FindByDateRange(r.Context(), from.AddDate(0, 0, -6), to.AddDate(0, 0, 1))
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"from": from,
+			"to":   to,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	tests, positive := series.Daily{}, series.Daily{}
+	for _, c := range counts {
+		if c.Date == nil {
+			continue
+		}
+		tests.Add(*c.Date, c.Tests)
+		positive.Add(*c.Date, c.Positive)
+	}
+
+	result := []testStats{}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		st := testStats{
+			Date:             day.Format(series.Layout),
+			Tests:            tests.Get(day),
+			Positive:         positive.Get(day),
+			SevenDayTests:    tests.Sum(day, 7),
+			SevenDayPositive: positive.Sum(day, 7),
+		}
+		st.Positivity = share(st.Positive, st.Tests)
+		st.SevenDayPositivity = share(st.SevenDayPositive, st.SevenDayTests)
+		result = append(result, st)
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
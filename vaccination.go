@@ -0,0 +1,59 @@
+package covidstats
+
+import (
+	"covidstats/stores"
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/gorilla/mux"
+)
+
+// HandleVaccinationStatus is the handler that returns the weekly breakdown
+// of cases and deaths by vaccination status for a year or an ISO week, or
+// for an epidemiological year or week.
+func (s *Server) HandleVaccinationStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleVaccinationStatus")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	vars := mux.Vars(r)
+	wq, queryErr := parseWeeklyQuery(vars)
+	if queryErr != nil {
+		http.Error(w, queryErr.Error(), http.StatusBadRequest)
+		return
+	}
+	svc := s.vaccinationStatus
+	if wq.epi {
+		svc = s.epiWeekVaccinationStatus
+	}
+	var (
+		counts  []stores.VaccinationStatusCount
+		findErr error
+	)
+	if wq.week == "" {
+		counts, findErr = svc.FindByYear(r.Context(), wq.year)
+	} else {
+		counts, findErr = svc.FindByWeek(r.Context(), wq.week)
+	}
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"vars": vars,
+		}).
+			WithError(findErr).
+			Error("finding vaccination status failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	if counts == nil {
+		counts = []stores.VaccinationStatusCount{}
+	}
+	if err := json.NewEncoder(w).Encode(counts); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,74 @@
+package covidstats
+
+import (
+	"covidstats/stores"
+	"covidstats/waves"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// parseWavesConfig reads the minPeak and prominence query parameters,
+// keeping the defaults of waves.DefaultConfig for those that are missing
+func parseWavesConfig(r *http.Request) (waves.Config, bool) {
+	cfg := waves.DefaultConfig()
+	q := r.URL.Query()
+	if value := q.Get("minPeak"); value != "" {
+		minPeak, err := strconv.ParseFloat(value, 64)
+		if err != nil || minPeak < 0 {
+			return cfg, false
+		}
+		cfg.MinPeak = minPeak
+	}
+	if value := q.Get("prominence"); value != "" {
+		prominence, err := strconv.ParseFloat(value, 64)
+		if err != nil || prominence <= 0 || prominence > 1 {
+			return cfg, false
+		}
+		cfg.Prominence = prominence
+	}
+	return cfg, true
+}
+
+// HandleWaves is the handler that returns the epidemic waves of the whole
+// outbreak, nationally or for the district query parameter. The minPeak and
+// prominence parameters set how marked a peak must be to make a wave.
+func (s *Server) HandleWaves(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+	s.logger.Info("HandleWaves")
+	if r.Method == http.MethodOptions {
+		return
+	}
+
+	district, districtErr := parseDistrict(r.URL.Query().Get("district"))
+	if districtErr != nil {
+		http.Error(w, districtErr.Error(), http.StatusBadRequest)
+		return
+	}
+	cfg, ok := parseWavesConfig(r)
+	if !ok {
+		http.Error(w, "minPeak must be a positive number and prominence a share between 0 and 1", http.StatusBadRequest)
+		return
+	}
+
+	end := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	cases, findErr := s.casesService.FindByDateRange(r.Context(), time.Time{}, end)
+	if findErr != nil {
+		s.logger.WithFields(log.Fields{
+			"to": end,
+		}).
+			WithError(findErr).
+			Error("FindByDateRange failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(waves.Find(stores.UnitCounts(cases, district), cfg)); err != nil {
+		s.logger.WithError(err).Error("encoding json response failed")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,151 @@
+// Package waves segments a daily case series into epidemic waves.
+//
+// The series is smoothed with a centred 7 day mean. A wave is a peak of the
+// smoothed series that stands out from its surroundings: the series must fall
+// by at least a share of the peak on either side before rising to a higher
+// peak, or reaching the end of the series. Consecutive waves are split at the
+// lowest day between their peaks.
+package waves
+
+import (
+	"covidstats/series"
+	"covidstats/stores"
+	"time"
+)
+
+// smoothing is the number of days of the centred mean
+const smoothing = 7
+
+// Config holds the thresholds a peak must meet to make a wave
+type Config struct {
+	// MinPeak is the smallest 7 day average of a peak
+	MinPeak float64
+	// Prominence is the share of its height the series must fall by on
+	// either side of a peak
+	Prominence float64
+}
+
+// DefaultConfig returns a config for peaks of at least 5 cases a day on
+// average that the series falls by half from on either side
+func DefaultConfig() Config {
+	return Config{MinPeak: 5, Prominence: 0.5}
+}
+
+// Wave is an epidemic wave of the series
+type Wave struct {
+	Number int       `json:"number"`
+	Start  time.Time `json:"start"`
+	Peak   time.Time `json:"peak"`
+	// PeakAverage is the centred 7 day average of the peak
+	PeakAverage float64 `json:"peakAverage"`
+	// End is nil while the wave is ongoing, that is while the series has
+	// not fallen by the prominence since the peak
+	End   *time.Time `json:"end"`
+	Cases int        `json:"cases"`
+}
+
+// Find returns the waves of the case series. Days missing from the series
+// count as zero cases. The last 3 days have no centred average and are only
+// counted in the cases of the last wave.
+func Find(counts []stores.CaseCount, cfg Config) []Wave {
+	daily := series.Daily{}
+	var last time.Time
+	for _, c := range counts {
+		if c.ReportingDate != nil {
+			daily.Add(*c.ReportingDate, c.Count)
+			if c.ReportingDate.After(last) {
+				last = *c.ReportingDate
+			}
+		}
+	}
+	first, ok := daily.First()
+	if !ok {
+		return []Wave{}
+	}
+
+	var days []time.Time
+	var smoothed []float64
+	for day := first; !day.AddDate(0, 0, smoothing/2).After(last); day = day.AddDate(0, 0, 1) {
+		days = append(days, day)
+		smoothed = append(smoothed, daily.Mean(day, smoothing, series.Centered))
+	}
+
+	peaks := prominentPeaks(smoothed, cfg)
+	waves := []Wave{}
+	for i, p := range peaks {
+		start := lowest(smoothed, 0, p, true)
+		if i > 0 {
+			start = lowest(smoothed, peaks[i-1], p, false) + 1
+		}
+		w := Wave{
+			Number:      i + 1,
+			Start:       days[start],
+			Peak:        days[p],
+			PeakAverage: smoothed[p],
+		}
+		endDay := last
+		if i < len(peaks)-1 {
+			end := lowest(smoothed, p, peaks[i+1], false)
+			endDay = days[end]
+			w.End = &endDay
+		} else if end := lowest(smoothed, p, len(smoothed)-1, false); smoothed[end] <= (1-cfg.Prominence)*smoothed[p] {
+			endDay = days[end]
+			w.End = &endDay
+		}
+		for day := w.Start; !day.After(endDay); day = day.AddDate(0, 0, 1) {
+			w.Cases += daily.Get(day)
+		}
+		waves = append(waves, w)
+	}
+	return waves
+}
+
+// prominentPeaks returns the indexes of the local maxima that meet the
+// thresholds, in order
+func prominentPeaks(s []float64, cfg Config) []int {
+	var peaks []int
+	for p := range s {
+		// The first day of a plateau is its peak.
+		if s[p] < cfg.MinPeak || (p > 0 && s[p-1] >= s[p]) || (p < len(s)-1 && s[p+1] > s[p]) {
+			continue
+		}
+		// The lowest point on each side before a higher peak.
+		left, right := s[p], s[p]
+		for i := p - 1; i >= 0 && s[i] <= s[p]; i-- {
+			if s[i] < left {
+				left = s[i]
+			}
+		}
+		i := p + 1
+		for ; i < len(s) && s[i] <= s[p]; i++ {
+			if s[i] < right {
+				right = s[i]
+			}
+		}
+		// A peak no higher one follows may still be falling, which makes it
+		// an ongoing wave rather than no wave at all.
+		if i == len(s) {
+			right = 0
+		}
+		base := left
+		if right > base {
+			base = right
+		}
+		if s[p]-base >= cfg.Prominence*s[p] {
+			peaks = append(peaks, p)
+		}
+	}
+	return peaks
+}
+
+// lowest returns the index of the lowest value from (inclusive) to to
+// (inclusive), the latest of equal values when latest is set
+func lowest(s []float64, from, to int, latest bool) int {
+	idx := from
+	for i := from; i <= to; i++ {
+		if s[i] < s[idx] || (latest && s[i] == s[idx]) {
+			idx = i
+		}
+	}
+	return idx
+}
@@ -0,0 +1,77 @@
+package waves
+
+import (
+	"covidstats/stores/storestest"
+	"math"
+	"testing"
+)
+
+// bump returns a bell shaped curve of the given height over the days
+func bump(days int, height float64) []int {
+	values := make([]int, days)
+	for i := range values {
+		x := (float64(i) - float64(days-1)/2) / (float64(days) / 6)
+		values[i] = int(math.Round(height * math.Exp(-x*x/2)))
+	}
+	return values
+}
+
+func TestFind(t *testing.T) {
+	// A wave peaking on 2020-09-09, a quiet spell and a second wave peaking
+	// on 2020-12-18 that has not fallen by half when the series ends.
+	var values []int
+	values = append(values, bump(60, 40)...)
+	values = append(values, make([]int, 20)...)
+	values = append(values, bump(100, 80)[:60]...)
+	waves := Find(storestest.Counts("2020-08-11", values...), DefaultConfig())
+
+	if len(waves) != 2 {
+		t.Fatalf("expected 2 waves, got %+v", waves)
+	}
+	first, second := waves[0], waves[1]
+	if first.Peak.Format("2006-01-02") != "2020-09-09" || math.Abs(first.PeakAverage-40) > 2 {
+		t.Errorf("unexpected peak of the first wave %+v", first)
+	}
+	if first.End == nil || first.End.After(second.Start) || first.Cases < 550 {
+		t.Errorf("unexpected first wave %+v", first)
+	}
+	total := 0
+	for _, v := range values[:60] {
+		total += v
+	}
+	if first.Cases != total {
+		t.Errorf("expected the first wave to hold its %d cases, got %d", total, first.Cases)
+	}
+	if second.Number != 2 || second.End != nil {
+		t.Errorf("expected the second wave to be ongoing, got %+v", second)
+	}
+	if second.Peak.Format("2006-01-02") != "2020-12-18" {
+		t.Errorf("expected the second peak on 2020-12-18, got %s", second.Peak.Format("2006-01-02"))
+	}
+}
+
+func TestFind_MergesSmallDips(t *testing.T) {
+	// A dip of a quarter of the peak does not split a wave.
+	var values []int
+	for i := 0; i < 20; i++ {
+		values = append(values, 2*i)
+	}
+	values = append(values, 40, 36, 32, 30, 32, 36, 40)
+	for i := 20; i > 0; i-- {
+		values = append(values, 2*i)
+	}
+	waves := Find(storestest.Counts("2021-03-01", append(values, make([]int, 10)...)...), DefaultConfig())
+	if len(waves) != 1 || waves[0].End == nil {
+		t.Errorf("expected a single finished wave, got %+v", waves)
+	}
+}
+
+func TestFind_Quiet(t *testing.T) {
+	values := []int{0, 1, 0, 2, 0, 0, 1, 3, 0, 1, 0, 0, 2, 0}
+	if waves := Find(storestest.Counts("2021-03-01", values...), DefaultConfig()); len(waves) != 0 {
+		t.Errorf("expected no wave below the minimum peak, got %+v", waves)
+	}
+	if waves := Find(nil, DefaultConfig()); len(waves) != 0 {
+		t.Errorf("expected no wave without cases, got %+v", waves)
+	}
+}